@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+)
+
+func configCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "inspect the runtime configuration",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "check",
+				Usage: "validate the environment configuration",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "ping",
+						Usage: "also verify the database is reachable",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					cfg, _, err := bootstrap()
+					if err != nil {
+						return fmt.Errorf("invalid configuration: %w", err)
+					}
+
+					tw := tabwriter.NewWriter(c.App.Writer, 0, 0, 2, ' ', 0)
+					fmt.Fprintf(tw, "ENV\t%s\n", cfg.Env)
+					fmt.Fprintf(tw, "LOG_LEVEL\t%s\n", cfg.LogLevel)
+					fmt.Fprintf(tw, "PORT\t%d\n", cfg.Port)
+					fmt.Fprintf(tw, "DATABASE_URL\t%s\n", redact(cfg.DatabaseURL))
+					fmt.Fprintf(tw, "DB_MAX_OPEN_CONNS\t%d\n", cfg.DBMaxOpenConns)
+					fmt.Fprintf(tw, "DB_MAX_IDLE_CONNS\t%d\n", cfg.DBMaxIdleConns)
+					fmt.Fprintf(tw, "DB_MAX_LIFETIME\t%s\n", cfg.DBMaxLifetime)
+					fmt.Fprintf(tw, "JWT_KEYS_DIR\t%s\n", orNotSet(cfg.JWTKeysDir))
+					fmt.Fprintf(tw, "JWT_ACTIVE_KID\t%s\n", orNotSet(cfg.JWTActiveKID))
+					fmt.Fprintf(tw, "LOGIN_MAX_ATTEMPTS\t%d\n", cfg.LoginMaxAttempts)
+					fmt.Fprintf(tw, "LOGIN_MAX_IP_ATTEMPTS\t%d\n", cfg.LoginMaxIPAttempts)
+					fmt.Fprintf(tw, "LOGIN_BACKOFF_BASE\t%s\n", cfg.LoginBackoffBase)
+					fmt.Fprintf(tw, "LOGIN_LOCKOUT_DURATION\t%s\n", cfg.LoginLockout)
+					fmt.Fprintf(tw, "TRUST_PROXY_HEADERS\t%t\n", cfg.TrustProxyHeaders)
+					fmt.Fprintf(tw, "MFA_ISSUER\t%s\n", cfg.MFAIssuer)
+					fmt.Fprintf(tw, "ADMIN_MFA_REQUIRED\t%t\n", cfg.AdminMFARequired)
+					fmt.Fprintf(tw, "CURSOR_SECRET\t%s\n", redact(cfg.CursorSecret))
+					fmt.Fprintf(tw, "PERSON_RETENTION\t%s\n", cfg.PersonRetention)
+					fmt.Fprintf(tw, "PERSON_PURGE_INTERVAL\t%s\n", cfg.PersonPurgeEvery)
+					fmt.Fprintf(tw, "IDEMPOTENCY_TTL\t%s\n", cfg.IdempotencyTTL)
+					fmt.Fprintf(tw, "MIGRATE_ON_START\t%t\n", cfg.MigrateOnStart)
+					if err := tw.Flush(); err != nil {
+						return err
+					}
+
+					if c.Bool("ping") {
+						db, err := openDB(cfg)
+						if err != nil {
+							return err
+						}
+						db.Close()
+						fmt.Fprintln(c.App.Writer, "database reachable")
+					}
+
+					fmt.Fprintln(c.App.Writer, "configuration OK")
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// redact hides secret values while still showing whether they are set.
+func redact(value string) string {
+	if value == "" {
+		return "(not set)"
+	}
+	return "(set)"
+}
+
+func orNotSet(value string) string {
+	if value == "" {
+		return "(not set)"
+	}
+	return value
+}
@@ -1,21 +1,21 @@
 package main
 
 import (
+	"crypto/rand"
 	"database/sql"
+	"fmt"
 	"log/slog"
 	"os"
-	"time"
 
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"github.com/urfave/cli/v2"
 
 	_ "github.com/izymalhaw/go-crud/yishakterefe/docs"
-	"github.com/izymalhaw/go-crud/yishakterefe/internal/api/handlers"
 	"github.com/izymalhaw/go-crud/yishakterefe/internal/config"
 	customlogger "github.com/izymalhaw/go-crud/yishakterefe/internal/core/logger"
 	"github.com/izymalhaw/go-crud/yishakterefe/internal/repository"
 	"github.com/izymalhaw/go-crud/yishakterefe/internal/services/auth"
-	person_service "github.com/izymalhaw/go-crud/yishakterefe/internal/services/person"
 )
 
 const (
@@ -26,48 +26,108 @@ func main() {
 	// Load .env variables
 	_ = godotenv.Load()
 
-	cfg, err := config.NewConfig()
-	if err != nil {
+	app := &cli.App{
+		Name:           "go-crud",
+		Usage:          "person API server and operational tooling",
+		Version:        version,
+		DefaultCommand: "serve",
+		Commands: []*cli.Command{
+			serveCommand(),
+			migrateCommand(),
+			seedCommand(),
+			personCommand(),
+			tokenCommand(),
+			configCommand(),
+		},
+	}
+
+	if err := app.Run(os.Args); err != nil {
 		slog.Error(err.Error())
 		os.Exit(1)
 	}
+}
+
+// bootstrap loads the configuration and builds the logger shared by every
+// command.
+func bootstrap() (*config.Config, *slog.Logger, error) {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// Logger
 	logger := customlogger.NewLogger(cfg.Env, cfg.LogLevel, version)
+	return cfg, logger, nil
+}
 
-	// DB connection
+// openDB connects to Postgres using the configured pool settings.
+func openDB(cfg *config.Config) (*sql.DB, error) {
 	db, err := sql.Open("postgres", cfg.DatabaseURL)
 	if err != nil {
-		logger.Error("failed to connect to database", "error", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
-	defer db.Close()
 
-	// DB pool config
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(25)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	db.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	db.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	db.SetConnMaxLifetime(cfg.DBMaxLifetime)
 
-	// Ping DB
 	if err = db.Ping(); err != nil {
-		logger.Error("failed to ping database", "error", err)
-		os.Exit(1)
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
-	logger.Info("database connected successfully")
-
-	// Initialize services
-	store := repository.NewPostgresUserRepo(db)
-	personService := person_service.NewPersonSvc(store)
+	return db, nil
+}
 
-	//  Initialize auth service
-	authService := auth.NewAuthService(cfg.JWTSecret)
+// cursorSecret returns the key that signs pagination cursors. Without
+// CURSOR_SECRET a random key is used, so cursors do not survive a restart.
+func cursorSecret(cfg *config.Config, logger *slog.Logger) ([]byte, error) {
+	if cfg.CursorSecret != "" {
+		return []byte(cfg.CursorSecret), nil
+	}
 
-	// Initialize auth handler
-	authHandler := handlers.NewAuthHandler(authService)
+	logger.Warn("CURSOR_SECRET not set, signing cursors with an ephemeral key")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
 
-	//  Pass all to handler.NewApp
-	webSrv := handlers.NewApp(cfg.Port , personService, authService, authHandler, logger)
+// newAuthService wires the auth service to its Postgres stores and signing
+// keys.
+func newAuthService(cfg *config.Config, db *sql.DB, logger *slog.Logger) (*auth.Service, error) {
+	var (
+		keys *auth.KeySet
+		err  error
+	)
+	if cfg.JWTKeysDir != "" {
+		keys, err = auth.LoadKeySet(cfg.JWTKeysDir, cfg.JWTActiveKID)
+	} else {
+		logger.Warn("JWT_KEYS_DIR not set, signing tokens with an ephemeral key")
+		keys, err = auth.GenerateKeySet()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing keys: %w", err)
+	}
 
-	logger.Info("server running")
-	webSrv.Run()
+	return auth.NewAuthService(auth.Config{
+		Keys:            keys,
+		AccessTokenTTL:  cfg.AccessTokenTTL,
+		RefreshTokenTTL: cfg.RefreshTokenTTL,
+		Lockout: auth.LockoutPolicy{
+			MaxAttempts:   cfg.LoginMaxAttempts,
+			MaxIPAttempts: cfg.LoginMaxIPAttempts,
+			BaseDelay:     cfg.LoginBackoffBase,
+			Duration:      cfg.LoginLockout,
+		},
+		MFAIssuer:       cfg.MFAIssuer,
+		RequireAdminMFA: cfg.AdminMFARequired,
+		Logger:          logger,
+	},
+		repository.NewPostgresUserStore(db),
+		repository.NewPostgresRefreshTokenStore(db),
+		repository.NewPostgresAPIKeyStore(db),
+		repository.NewPostgresRevocationStore(db),
+		repository.NewPostgresLoginAttemptStore(db),
+		repository.NewPostgresMFAStore(db),
+	), nil
 }
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/repository/migrations"
+)
+
+func migrateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "migrate",
+		Usage: "manage the database schema",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "up",
+				Usage: "apply all pending migrations",
+				Action: func(c *cli.Context) error {
+					return withMigrator(func(m *migrations.Migrator) error {
+						applied, err := m.Up(c.Context)
+						if err != nil {
+							return err
+						}
+						fmt.Fprintf(c.App.Writer, "applied %d migration(s)\n", applied)
+						return nil
+					})
+				},
+			},
+			{
+				Name:  "down",
+				Usage: "roll back the most recent migrations",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "steps",
+						Usage: "number of migrations to roll back",
+						Value: 1,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					steps := c.Int("steps")
+					if steps <= 0 {
+						return fmt.Errorf("steps must be positive, got %d", steps)
+					}
+					return withMigrator(func(m *migrations.Migrator) error {
+						rolledBack, err := m.Down(c.Context, steps)
+						if err != nil {
+							return err
+						}
+						fmt.Fprintf(c.App.Writer, "rolled back %d migration(s)\n", rolledBack)
+						return nil
+					})
+				},
+			},
+			{
+				Name:  "status",
+				Usage: "list migrations and whether they are applied",
+				Action: func(c *cli.Context) error {
+					return withMigrator(func(m *migrations.Migrator) error {
+						statuses, err := m.Status(c.Context)
+						if err != nil {
+							return err
+						}
+
+						tw := tabwriter.NewWriter(c.App.Writer, 0, 0, 2, ' ', 0)
+						fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+						for _, s := range statuses {
+							appliedAt := "pending"
+							if s.Applied {
+								appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+							}
+							fmt.Fprintf(tw, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+						}
+						return tw.Flush()
+					})
+				},
+			},
+		},
+	}
+}
+
+func withMigrator(fn func(m *migrations.Migrator) error) error {
+	cfg, logger, err := bootstrap()
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migrations.NewMigrator(db, logger)
+	if err != nil {
+		return err
+	}
+	return fn(migrator)
+}
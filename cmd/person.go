@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/repository"
+	person_service "github.com/izymalhaw/go-crud/yishakterefe/internal/services/person"
+)
+
+// personFormats maps the --format flag to a person media type.
+var personFormats = map[string]person_service.PersonFormat{
+	"csv":    person_service.CSV,
+	"ndjson": person_service.NDJSON,
+}
+
+func formatFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "format",
+		Aliases: []string{"f"},
+		Usage:   "record format: ndjson or csv (hobbies joined by \";\")",
+		Value:   "ndjson",
+	}
+}
+
+func personFormat(c *cli.Context) (person_service.PersonFormat, error) {
+	format, ok := personFormats[c.String("format")]
+	if !ok {
+		return "", fmt.Errorf("unknown format %q: want ndjson or csv", c.String("format"))
+	}
+	return format, nil
+}
+
+func personCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "person",
+		Usage: "export and import person records",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "export",
+				Usage: "write every person as newline-delimited JSON or CSV",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "file to write to (default: stdout)",
+					},
+					formatFlag(),
+				},
+				Action: func(c *cli.Context) error {
+					format, err := personFormat(c)
+					if err != nil {
+						return err
+					}
+					out := c.App.Writer
+					if path := c.String("output"); path != "" {
+						f, err := os.Create(path)
+						if err != nil {
+							return err
+						}
+						defer f.Close()
+						out = f
+					}
+
+					return withPersonService(func(svc person_service.PersonServiceAbstrcatImpl) error {
+						w, err := person_service.NewPersonWriter(out, format)
+						if err != nil {
+							return err
+						}
+						count := 0
+						err = svc.ExportPersons(c.Context, domain.DefaultPersonQuery(person_service.ExportPageSize, 0), func(p domain.Person) error {
+							count++
+							return w.Write(p)
+						})
+						if err != nil {
+							return err
+						}
+						if err := w.Flush(); err != nil {
+							return err
+						}
+						fmt.Fprintf(c.App.ErrWriter, "exported %d person(s)\n", count)
+						return nil
+					})
+				},
+			},
+			{
+				Name:  "import",
+				Usage: "create persons from newline-delimited JSON or CSV",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "input",
+						Aliases: []string{"i"},
+						Usage:   "file to read from (default: stdin)",
+					},
+					formatFlag(),
+				},
+				Action: func(c *cli.Context) error {
+					format, err := personFormat(c)
+					if err != nil {
+						return err
+					}
+					var in io.Reader = os.Stdin
+					if path := c.String("input"); path != "" {
+						f, err := os.Open(path)
+						if err != nil {
+							return err
+						}
+						defer f.Close()
+						in = f
+					}
+
+					return withPersonService(func(svc person_service.PersonServiceAbstrcatImpl) error {
+						report, err := svc.ImportPersons(c.Context, in, format)
+						for _, rejected := range report.Rejected {
+							fmt.Fprintf(c.App.ErrWriter, "record %d: %v\n", rejected.Row, rejected.Err)
+						}
+						fmt.Fprintf(c.App.ErrWriter, "imported %d person(s), rejected %d\n", report.Imported, len(report.Rejected))
+						if err != nil {
+							return err
+						}
+						if len(report.Rejected) > 0 {
+							return cli.Exit("", 1)
+						}
+						return nil
+					})
+				},
+			},
+		},
+	}
+}
+
+func withPersonService(fn func(svc person_service.PersonServiceAbstrcatImpl) error) error {
+	cfg, logger, err := bootstrap()
+	if err != nil {
+		return err
+	}
+	secret, err := cursorSecret(cfg, logger)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	store := repository.NewPostgresUserRepo(db)
+	return fn(person_service.NewPersonSvc(store, secret))
+}
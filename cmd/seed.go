@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/urfave/cli/v2"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	person_service "github.com/izymalhaw/go-crud/yishakterefe/internal/services/person"
+)
+
+var seedPersons = []domain.Person{
+	{Name: "Abebe Bikila", Age: 41, Hobbies: []string{"running", "reading"}},
+	{Name: "Tirunesh Dibaba", Age: 39, Hobbies: []string{"running", "cooking"}},
+	{Name: "Haile Gebrselassie", Age: 52, Hobbies: []string{"running", "business", "football"}},
+	{Name: "Meseret Defar", Age: 41, Hobbies: []string{"music", "running"}},
+	{Name: "Kenenisa Bekele", Age: 43, Hobbies: []string{"running", "farming"}},
+	{Name: "Derartu Tulu", Age: 53, Hobbies: []string{"coaching", "travel"}},
+	{Name: "Genzebe Dibaba", Age: 34, Hobbies: []string{"running", "photography"}},
+	{Name: "Almaz Ayana", Age: 33, Hobbies: []string{"running", "painting"}},
+	{Name: "Yomif Kejelcha", Age: 28, Hobbies: []string{"gaming", "running"}},
+	{Name: "Letesenbet Gidey", Age: 27, Hobbies: []string{"running", "chess"}},
+}
+
+func seedCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "seed",
+		Usage: "insert sample persons for local development",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "count",
+				Usage: "number of sample persons to insert",
+				Value: len(seedPersons),
+			},
+		},
+		Action: func(c *cli.Context) error {
+			count := c.Int("count")
+			if count <= 0 {
+				return fmt.Errorf("count must be positive, got %d", count)
+			}
+
+			return withPersonService(func(svc person_service.PersonServiceAbstrcatImpl) error {
+				for i := 0; i < count; i++ {
+					p := seedPersons[i%len(seedPersons)]
+					p.Id = uuid.New()
+					if err := svc.CreatePerson(c.Context, p); err != nil {
+						return err
+					}
+				}
+				fmt.Fprintf(c.App.Writer, "seeded %d person(s)\n", count)
+				return nil
+			})
+		},
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/api/handlers"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/repository"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/repository/migrations"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/services/idempotency"
+	person_service "github.com/izymalhaw/go-crud/yishakterefe/internal/services/person"
+)
+
+func serveCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "serve",
+		Usage: "start the HTTP API server",
+		Action: func(c *cli.Context) error {
+			cfg, logger, err := bootstrap()
+			if err != nil {
+				return err
+			}
+
+			// DB connection
+			db, err := openDB(cfg)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			logger.Info("database connected successfully")
+
+			// Apply schema migrations
+			if cfg.MigrateOnStart {
+				migrator, err := migrations.NewMigrator(db, logger)
+				if err != nil {
+					return err
+				}
+				applied, err := migrator.Up(c.Context)
+				if err != nil {
+					return err
+				}
+				logger.Info("database migrations applied", "count", applied)
+			}
+
+			// Initialize services
+			secret, err := cursorSecret(cfg, logger)
+			if err != nil {
+				return err
+			}
+			store := repository.NewPostgresUserRepo(db)
+			personService := person_service.NewPersonSvc(store, secret)
+
+			//  Initialize auth service
+			authService, err := newAuthService(cfg, db, logger)
+			if err != nil {
+				return err
+			}
+
+			// Bootstrap the administrator account
+			if cfg.AdminEmail != "" && cfg.AdminPassword != "" {
+				admin, created, err := authService.EnsureUser(c.Context, cfg.AdminEmail, cfg.AdminPassword, domain.RoleAdmin)
+				if err != nil {
+					return err
+				}
+				if created {
+					logger.Info("admin account created", "user_id", admin.Id)
+				}
+			}
+
+			// Initialize auth handler
+			authHandler := handlers.NewAuthHandler(authService, cfg.TrustProxyHeaders, logger)
+
+			idempotencyService := idempotency.NewIdempotencyService(repository.NewPostgresIdempotencyStore(db), cfg.IdempotencyTTL)
+
+			//  Pass all to handler.NewApp
+			webSrv := handlers.NewApp(cfg.Port, personService, authService, authHandler, idempotencyService, logger)
+
+			// Prune expired token revocations, stale login attempts and
+			// idempotency keys, and purge persons deleted beyond the
+			// retention period, until the server stops
+			ctx, cancel := context.WithCancel(c.Context)
+			defer cancel()
+			go authService.RunPruner(ctx, cfg.PruneInterval, logger)
+			go idempotencyService.RunPruner(ctx, cfg.PruneInterval, logger)
+			go personService.RunPurger(ctx, cfg.PersonPurgeEvery, cfg.PersonRetention, logger)
+
+			logger.Info("server running")
+			return webSrv.Run()
+		},
+	}
+}
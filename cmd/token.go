@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/repository"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/services/auth"
+)
+
+func tokenCommand() *cli.Command {
+	keysDirFlag := &cli.StringFlag{
+		Name:     "dir",
+		Usage:    "directory holding the signing keys",
+		EnvVars:  []string{"JWT_KEYS_DIR"},
+		Required: true,
+	}
+
+	return &cli.Command{
+		Name:  "token",
+		Usage: "manage API access tokens and signing keys",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "issue",
+				Usage: "sign an access token for an existing user",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "email",
+						Usage:    "email of the user the token is issued to",
+						Required: true,
+					},
+					&cli.BoolFlag{
+						Name: "mfa",
+						Usage: "mark the token as second-factor verified; without it an admin token " +
+							"is refused on person routes while ADMIN_MFA_REQUIRED is set",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					cfg, logger, err := bootstrap()
+					if err != nil {
+						return err
+					}
+					// An ephemeral key would not be trusted by any running server.
+					if cfg.JWTKeysDir == "" {
+						return errors.New("token issue requires JWT_KEYS_DIR")
+					}
+
+					db, err := openDB(cfg)
+					if err != nil {
+						return err
+					}
+					defer db.Close()
+
+					userStore := repository.NewPostgresUserStore(db)
+					user, err := userStore.GetUserByEmail(c.Context, c.String("email"))
+					if err != nil {
+						return err
+					}
+
+					authService, err := newAuthService(cfg, db, logger)
+					if err != nil {
+						return err
+					}
+					token, err := authService.GenerateToken(*user, c.Bool("mfa"))
+					if err != nil {
+						return err
+					}
+					fmt.Fprintln(c.App.Writer, token)
+					return nil
+				},
+			},
+			{
+				Name:  "keygen",
+				Usage: "generate a new signing key in the keys directory",
+				Flags: []cli.Flag{
+					keysDirFlag,
+					&cli.StringFlag{
+						Name:  "alg",
+						Usage: "signing algorithm (RS256 or EdDSA)",
+						Value: auth.AlgEdDSA,
+					},
+					&cli.StringFlag{
+						Name:  "kid",
+						Usage: "key ID (default: current UTC timestamp)",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					kid := c.String("kid")
+					if kid == "" {
+						kid = time.Now().UTC().Format("20060102T150405Z")
+					}
+
+					data, err := auth.GenerateKeyPEM(c.String("alg"))
+					if err != nil {
+						return err
+					}
+
+					path := filepath.Join(c.String("dir"), kid+".pem")
+					if err := os.WriteFile(path, data, 0o600); err != nil {
+						return err
+					}
+					fmt.Fprintf(c.App.Writer, "wrote %s; set JWT_ACTIVE_KID=%s to start signing with it\n", path, kid)
+					return nil
+				},
+			},
+			{
+				Name:  "retire",
+				Usage: "stop signing with a key but keep verifying tokens it issued",
+				Flags: []cli.Flag{
+					keysDirFlag,
+					&cli.StringFlag{
+						Name:     "kid",
+						Usage:    "key ID to retire",
+						Required: true,
+					},
+				},
+				Action: func(c *cli.Context) error {
+					path := filepath.Join(c.String("dir"), c.String("kid")+".pem")
+					data, err := os.ReadFile(path)
+					if err != nil {
+						return err
+					}
+
+					public, err := auth.PublicKeyPEM(data)
+					if err != nil {
+						return err
+					}
+					if err := os.WriteFile(path, public, 0o644); err != nil {
+						return err
+					}
+					fmt.Fprintf(c.App.Writer, "retired %s; delete the file once its tokens have expired\n", path)
+					return nil
+				},
+			},
+		},
+	}
+}
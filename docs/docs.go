@@ -15,6 +15,122 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/v1/admin/persons/deleted": {
+            "get": {
+                "description": "Lists deleted persons that can still be restored, with the same filters, sorting and pagination as the person list.",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "text/csv"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List deleted persons",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Limit number of results, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Case-insensitive substring of the name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Free-text search; every word must prefix a word of the name or a hobby",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "updated_at",
+                            "name",
+                            "age"
+                        ],
+                        "type": "string",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort direction",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from a previous page's links; replaces offset",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.ListResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/person": {
             "get": {
                 "description": "Retrieves a list of persons with optional pagination.",
@@ -22,7 +138,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack",
+                    "text/csv"
                 ],
                 "tags": [
                     "person"
@@ -31,7 +150,7 @@ const docTemplate = `{
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Limit number of results",
+                        "description": "Limit number of results, at most 100",
                         "name": "limit",
                         "in": "query"
                     },
@@ -40,54 +159,161 @@ const docTemplate = `{
                         "description": "Offset for pagination",
                         "name": "offset",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Case-insensitive substring of the name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Case-insensitive prefix of the name",
+                        "name": "name_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum age, inclusive",
+                        "name": "min_age",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum age, inclusive",
+                        "name": "max_age",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Hobby the person must have; repeat to require several",
+                        "name": "hobby",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Free-text search; every word must prefix a word of the name or a hobby",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "updated_at",
+                            "name",
+                            "age"
+                        ],
+                        "type": "string",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort direction",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque cursor from a previous page's links; replaces offset",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.ListResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "RFC 8288 links to the first, previous and next pages"
                             }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/person/create": {
+        "/api/v1/person/batch/create": {
             "post": {
-                "description": "This endpoint creates a new person entry.",
+                "description": "Creates up to 5000 persons. In atomic mode (the default) either all of them are created or none is; in best_effort mode every valid person is created. The result of each person reports its ID and the status it would have had as a single request.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "person"
                 ],
-                "summary": "Create a new person",
+                "summary": "Create persons in a batch",
                 "parameters": [
                     {
-                        "description": "Person Data",
-                        "name": "dto.PersonRequest",
+                        "enum": [
+                            "atomic",
+                            "best_effort"
+                        ],
+                        "type": "string",
+                        "description": "Whether one failed person fails the batch",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Persons",
+                        "name": "persons",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonRequest"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonRequest"
+                            }
                         }
                     }
                 ],
@@ -95,94 +321,241 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonBatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonBatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonBatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/v1/person/{personId}": {
-            "get": {
-                "description": "This endpoint retrieves a single person entry.",
+        "/api/v1/person/batch/delete": {
+            "post": {
+                "description": "Deletes up to 5000 persons, each only at its version when one is given. In atomic mode (the default) either all of them are deleted or none is; in best_effort mode every person that can be is deleted.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "person"
                 ],
-                "summary": "Get Single person",
+                "summary": "Delete persons in a batch",
                 "parameters": [
                     {
+                        "enum": [
+                            "atomic",
+                            "best_effort"
+                        ],
                         "type": "string",
-                        "description": "Person ID",
-                        "name": "personId",
-                        "in": "path",
-                        "required": true
+                        "description": "Whether one failed person fails the batch",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Persons",
+                        "name": "persons",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonBatchDeleteRequest"
+                            }
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonBatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonBatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonBatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "This endpoint updates a person's details.",
+            }
+        },
+        "/api/v1/person/batch/update": {
+            "post": {
+                "description": "Replaces up to 5000 persons, each only at its version when one is given. In atomic mode (the default) either all of them are updated or none is; in best_effort mode every person that can be is updated.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "person"
                 ],
-                "summary": "Update a person",
+                "summary": "Update persons in a batch",
                 "parameters": [
                     {
+                        "enum": [
+                            "atomic",
+                            "best_effort"
+                        ],
                         "type": "string",
-                        "description": "Person ID",
-                        "name": "personId",
-                        "in": "path",
-                        "required": true
+                        "description": "Whether one failed person fails the batch",
+                        "name": "mode",
+                        "in": "query"
                     },
                     {
-                        "description": "Person Data",
-                        "name": "dto.PersonRequest",
+                        "description": "Persons",
+                        "name": "persons",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonRequest"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonBatchUpdateRequest"
+                            }
                         }
                     }
                 ],
@@ -190,88 +563,972 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonBatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonBatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonBatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "This endpoint deletes a person entry.",
+            }
+        },
+        "/api/v1/person/create": {
+            "post": {
+                "description": "This endpoint creates a new person entry.",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "person"
                 ],
-                "summary": "Delete person",
+                "summary": "Create a new person",
                 "parameters": [
+                    {
+                        "description": "Person Data",
+                        "name": "dto.PersonRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonRequest"
+                        }
+                    },
                     {
                         "type": "string",
-                        "description": "Person ID",
-                        "name": "personId",
-                        "in": "path",
-                        "required": true
+                        "description": "Replays the first response to retries with the same key and body",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully deleted person",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonRequest": {
-            "type": "object",
-            "required": [
-                "age",
-                "hobbies",
-                "name"
-            ],
+        },
+        "/api/v1/person/export": {
+            "get": {
+                "description": "Streams every person matching the filters as CSV (hobbies joined by \";\") or NDJSON. The format is taken from the format parameter, else from the Accept header, and defaults to NDJSON.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Export persons",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Export format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Case-insensitive substring of the name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Case-insensitive prefix of the name",
+                        "name": "name_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum age, inclusive",
+                        "name": "min_age",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum age, inclusive",
+                        "name": "max_age",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Hobby the person must have; repeat to require several",
+                        "name": "hobby",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Free-text search; every word must prefix a word of the name or a hobby",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "updated_at",
+                            "name",
+                            "age"
+                        ],
+                        "type": "string",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort direction",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Persons",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/person/import": {
+            "post": {
+                "description": "Creates persons from a CSV with a header row (columns id, name, age and hobbies, with hobbies joined by \";\"; only name and age are required) or from NDJSON. Every row is validated on its own and rows that fail are reported without stopping the import.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Import persons",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonImportReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonImportReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonImportReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonImportReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonImportReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/person/{personId}": {
+            "get": {
+                "description": "This endpoint retrieves a single person entry. Its ETag can be sent back in If-None-Match to skip an unchanged body, or in If-Match to make a write conditional.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Get Single person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person ID",
+                        "name": "personId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Entity tag of the person"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "This endpoint stores a person under the given ID: it creates the person if the ID is unused and otherwise updates their details. Send the ETag from a previous read in If-Match to avoid overwriting someone else's change; If-Match never creates a person.",
+                "consumes": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Create or update a person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person ID",
+                        "name": "personId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the person must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Person Data",
+                        "name": "dto.PersonRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Entity tag of the updated person"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Entity tag of the created person"
+                            },
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the created person"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "This endpoint deletes a person entry. The person can be restored until it is purged after the retention period.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Delete person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person ID",
+                        "name": "personId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the person must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully deleted person",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Applies a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) to a person. Only the fields the patch changes are written.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Partially update a person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person ID",
+                        "name": "personId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the person must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Merge patch object or JSON Patch operation array",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Entity tag of the patched person"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/person/{personId}/restore": {
+            "post": {
+                "description": "Brings back a deleted person that has not been purged yet.",
+                "produces": [
+                    "application/json",
+                    "text/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Restore a deleted person",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Person ID",
+                        "name": "personId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonResponse"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Entity tag of the restored person"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonBatchDeleteRequest": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonBatchResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonBatchResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonBatchResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonBatchUpdateRequest": {
+            "type": "object",
+            "required": [
+                "hobbies",
+                "name"
+            ],
             "properties": {
                 "age": {
                     "type": "integer",
+                    "maximum": 150,
                     "minimum": 0
                 },
                 "hobbies": {
                     "type": "array",
+                    "maxItems": 20,
                     "items": {
                         "type": "string"
                     }
                 },
+                "id": {
+                    "type": "string"
+                },
                 "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonImportRejection": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonImportReport": {
+            "type": "object",
+            "properties": {
+                "imported": {
+                    "type": "integer"
+                },
+                "rejected": {
+                    "type": "integer"
+                },
+                "rejected_rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonImportRejection"
+                    }
+                }
+            }
+        },
+        "github_com_izymalhaw_go-crud_yishakterefe_internal_api_dto.PersonRequest": {
+            "type": "object",
+            "required": [
+                "hobbies",
+                "name"
+            ],
+            "properties": {
+                "age": {
+                    "type": "integer",
+                    "maximum": 150,
+                    "minimum": 0
+                },
+                "hobbies": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
                 }
             }
         },
@@ -281,6 +1538,9 @@ const docTemplate = `{
                 "age": {
                     "type": "integer"
                 },
+                "deleted_at": {
+                    "type": "string"
+                },
                 "hobbies": {
                     "type": "array",
                     "items": {
@@ -294,6 +1554,100 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "github_com_izymalhaw_go-crud_yishakterefe_internal_util.APIResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "message": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_izymalhaw_go-crud_yishakterefe_internal_util.ListResponse": {
+            "type": "object",
+            "properties": {
+                "cursor": {
+                    "type": "string"
+                },
+                "data": {},
+                "limit": {
+                    "type": "integer"
+                },
+                "links": {
+                    "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.PageLinks"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_izymalhaw_go-crud_yishakterefe_internal_util.PageLinks": {
+            "type": "object",
+            "properties": {
+                "first": {
+                    "type": "string"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "prev": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_izymalhaw_go-crud_yishakterefe_internal_util.Problem": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_izymalhaw_go-crud_yishakterefe_internal_util.ProblemField"
+                    }
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_izymalhaw_go-crud_yishakterefe_internal_util.ProblemField": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "pointer": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -1,16 +1,69 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"time"
 
+	"github.com/google/uuid"
+)
+
+// PersonRequest is the body of a person create or update. Its validate
+// tags state the limits of domain.Person.Validate.
 type PersonRequest struct {
-	Name    string   `json:"name" validate:"required"`
-	Age     int      `json:"age" validate:"required,gte=0"`
-	Hobbies []string `json:"hobbies" validate:"required"`
+	Name    string   `json:"name" xml:"name" validate:"required,max=100,personname"`
+	Age     int      `json:"age" xml:"age" validate:"gte=0,lte=150"`
+	Hobbies []string `json:"hobbies" xml:"hobbies>hobby" validate:"max=20,uniquefold,dive,required,max=50,hobby"`
 }
 
 type PersonResponse struct {
-	Id      uuid.UUID `json:"id"`
-	Name    string    `json:"name"`
-	Age     int       `json:"age"`
-	Hobbies []string  `json:"hobbies"`
+	Id        uuid.UUID  `json:"id" xml:"id"`
+	Name      string     `json:"name" xml:"name"`
+	Age       int        `json:"age" xml:"age"`
+	Hobbies   []string   `json:"hobbies" xml:"hobbies>hobby"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" xml:"deleted_at,omitempty"`
+}
+
+// PersonBatchUpdateRequest is one person of a batch update. A non-zero
+// Version makes its update conditional, like If-Match on a single update.
+type PersonBatchUpdateRequest struct {
+	Id      uuid.UUID `json:"id" xml:"id"`
+	Version int64     `json:"version,omitempty" xml:"version,omitempty"`
+	PersonRequest
+}
+
+// PersonBatchDeleteRequest is one person of a batch delete.
+type PersonBatchDeleteRequest struct {
+	Id      uuid.UUID `json:"id" xml:"id"`
+	Version int64     `json:"version,omitempty" xml:"version,omitempty"`
+}
+
+// PersonBatchResult is the outcome of the batch item at Index. Status is
+// the HTTP status the item would have had as a single request.
+type PersonBatchResult struct {
+	Index   int       `json:"index" xml:"index"`
+	Id      uuid.UUID `json:"id" xml:"id"`
+	Status  int       `json:"status" xml:"status"`
+	Version int64     `json:"version,omitempty" xml:"version,omitempty"`
+	Error   string    `json:"error,omitempty" xml:"error,omitempty"`
+}
+
+type PersonBatchResponse struct {
+	Succeeded int                 `json:"succeeded" xml:"succeeded"`
+	Failed    int                 `json:"failed" xml:"failed"`
+	Results   []PersonBatchResult `json:"results" xml:"results>result"`
+}
+
+// PersonImportReport sums up an import. RejectedRows lists the rows that
+// were not imported and why.
+type PersonImportReport struct {
+	Imported     int                     `json:"imported" xml:"imported"`
+	Rejected     int                     `json:"rejected" xml:"rejected"`
+	RejectedRows []PersonImportRejection `json:"rejected_rows" xml:"rejected_rows>row"`
+}
+
+// PersonImportRejection is a rejected import row. Row counts the data rows
+// of a CSV, without the header, and the lines of NDJSON, from 1.
+type PersonImportRejection struct {
+	Row   int        `json:"row" xml:"row"`
+	Id    *uuid.UUID `json:"id,omitempty" xml:"id,omitempty"`
+	Error string     `json:"error" xml:"error"`
 }
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/api/dto"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/util"
+)
+
+func (h *AuthHandler) CreateAPIKey() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := sessionUserID(w, r)
+		if !ok {
+			return
+		}
+		if !h.requireSecondFactor(w, r) {
+			return
+		}
+
+		var req dto.CreateAPIKeyRequest
+		if err := util.DecodeJSON(w, r, &req); err != nil {
+			util.WriteDomainError(w, err, "Invalid request body")
+			return
+		}
+		if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+			util.WriteErrorResponse(w, http.StatusBadRequest, "expires_at must be in the future")
+			return
+		}
+
+		scopes := make([]domain.Permission, len(req.Scopes))
+		for i, scope := range req.Scopes {
+			scopes[i] = domain.Permission(scope)
+		}
+
+		key, raw, err := h.authService.CreateAPIKey(r.Context(), userID, req.Name, scopes, req.ExpiresAt)
+		if err != nil {
+			h.writeError(w, err, "Failed to manage API key")
+			return
+		}
+
+		util.WriteSuccessResponse(w, dto.IssuedAPIKeyResponse{
+			APIKeyResponse: apiKeyResponse(key),
+			Key:            raw,
+		}, "API key created; store it now, it will not be shown again")
+	}
+}
+
+func (h *AuthHandler) ListAPIKeys() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := sessionUserID(w, r)
+		if !ok {
+			return
+		}
+
+		keys, err := h.authService.ListAPIKeys(r.Context(), userID)
+		if err != nil {
+			h.writeError(w, err, "Failed to list API keys")
+			return
+		}
+
+		response := make([]dto.APIKeyResponse, 0, len(keys))
+		for _, key := range keys {
+			response = append(response, apiKeyResponse(key))
+		}
+		util.WriteSuccessResponse(w, response, "API keys retrieved successfully")
+	}
+}
+
+func (h *AuthHandler) RevokeAPIKey() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := sessionUserID(w, r)
+		if !ok {
+			return
+		}
+		keyID, err := uuid.Parse(r.PathValue("keyId"))
+		if err != nil {
+			util.WriteErrorResponse(w, http.StatusBadRequest, "Invalid API key ID")
+			return
+		}
+
+		if err := h.authService.RevokeAPIKey(r.Context(), userID, keyID); err != nil {
+			h.writeError(w, err, "Failed to manage API key")
+			return
+		}
+		util.WriteSuccessResponse(w, nil, "API key revoked")
+	}
+}
+
+func (h *AuthHandler) RotateAPIKey() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := sessionUserID(w, r)
+		if !ok {
+			return
+		}
+		if !h.requireSecondFactor(w, r) {
+			return
+		}
+		keyID, err := uuid.Parse(r.PathValue("keyId"))
+		if err != nil {
+			util.WriteErrorResponse(w, http.StatusBadRequest, "Invalid API key ID")
+			return
+		}
+
+		key, raw, err := h.authService.RotateAPIKey(r.Context(), userID, keyID)
+		if err != nil {
+			h.writeError(w, err, "Failed to manage API key")
+			return
+		}
+
+		util.WriteSuccessResponse(w, dto.IssuedAPIKeyResponse{
+			APIKeyResponse: apiKeyResponse(key),
+			Key:            raw,
+		}, "API key rotated; store it now, it will not be shown again")
+	}
+}
+
+// sessionUserID returns the ID of a caller that logged in interactively.
+// API keys cannot manage credentials, so a key-authenticated caller gets 403.
+func sessionUserID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	principal, ok := PrincipalFromContext(r.Context())
+	if !ok {
+		util.WriteErrorResponse(w, http.StatusUnauthorized, "Authentication required")
+		return uuid.Nil, false
+	}
+	if principal.APIKeyID != "" {
+		util.WriteErrorResponse(w, http.StatusForbidden, "Forbidden: API keys cannot manage credentials")
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(principal.Subject)
+	if err != nil {
+		util.WriteErrorResponse(w, http.StatusUnauthorized, "Invalid token subject")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// requireSecondFactor stops admins who skipped the second factor from
+// issuing API keys, which would otherwise let them bypass it.
+func (h *AuthHandler) requireSecondFactor(w http.ResponseWriter, r *http.Request) bool {
+	principal, _ := PrincipalFromContext(r.Context())
+	if h.authService.MFARequired(principal) {
+		util.WriteErrorResponse(w, http.StatusForbidden, "Forbidden: sign in with two-factor authentication first")
+		return false
+	}
+	return true
+}
+
+func apiKeyResponse(key domain.APIKey) dto.APIKeyResponse {
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+	return dto.APIKeyResponse{
+		Id:         key.Id,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -13,7 +13,9 @@ import (
 	"time"
 
 	"github.com/izymalhaw/go-crud/yishakterefe/internal/services/auth"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/services/idempotency"
 	person_service "github.com/izymalhaw/go-crud/yishakterefe/internal/services/person"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/util"
 )
 
 // Server represents the HTTP server for the application.
@@ -23,6 +25,7 @@ type Server struct {
 	PersonService person_service.PersonServiceAbstrcatImpl
 	authService   *auth.Service
 	authHandler   *AuthHandler
+	idempotency   *idempotency.Service
 	logger        *slog.Logger
 }
 
@@ -32,6 +35,7 @@ func NewApp(
 	personService person_service.PersonServiceAbstrcatImpl,
 	authService *auth.Service,
 	authHandler *AuthHandler,
+	idempotencyService *idempotency.Service,
 	logger *slog.Logger,
 ) *Server {
 	app := &Server{
@@ -40,14 +44,15 @@ func NewApp(
 		PersonService: personService,
 		authService:   authService,
 		authHandler:   authHandler,
+		idempotency:   idempotencyService,
 		logger:        logger,
 	}
 
 	// Register routes
 	app.Routes()
 
-	// Enable CORS
-	wrappedRouter := app.enableCORS(app.router)
+	// Enable CORS and tag every request with an ID
+	wrappedRouter := util.RequestID(app.enableCORS(app.router))
 
 	// Replace the original router with wrapped one
 	app.router = http.NewServeMux()
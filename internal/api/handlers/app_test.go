@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/repository"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/services/auth"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/services/idempotency"
+	person_service "github.com/izymalhaw/go-crud/yishakterefe/internal/services/person"
+)
+
+// testApp is a server over in-memory stores with an admin signed in.
+type testApp struct {
+	*Server
+	persons *repository.InMemoryUserRepo
+	token   string
+}
+
+func newTestApp(t *testing.T) testApp {
+	t.Helper()
+	keys, err := auth.GenerateKeySet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	authService := auth.NewAuthService(
+		auth.Config{
+			Keys:            keys,
+			AccessTokenTTL:  time.Minute,
+			RefreshTokenTTL: time.Hour,
+			Lockout:         auth.LockoutPolicy{MaxAttempts: 5, MaxIPAttempts: 20, BaseDelay: time.Millisecond, Duration: time.Minute},
+			Logger:          logger,
+		},
+		repository.NewInMemoryUserStore(),
+		repository.NewInMemoryRefreshTokenStore(),
+		repository.NewInMemoryAPIKeyStore(),
+		repository.NewInMemoryRevocationStore(),
+		repository.NewInMemoryLoginAttemptStore(),
+		repository.NewInMemoryMFAStore(),
+	)
+	admin, _, err := authService.EnsureUser(context.Background(), "admin@example.com", "admin password", domain.RoleAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := authService.GenerateToken(admin, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	persons := repository.NewInMemoryUserRepo()
+	server := NewApp(0,
+		person_service.NewPersonSvc(persons, []byte("cursor secret")),
+		authService,
+		NewAuthHandler(authService, false, logger),
+		idempotency.NewIdempotencyService(repository.NewInMemoryIdempotencyStore(), time.Hour),
+		logger,
+	)
+	return testApp{Server: server, persons: persons, token: token}
+}
+
+// tokenFor signs in a new user with role and returns its access token.
+func (app testApp) tokenFor(t *testing.T, role domain.Role) string {
+	t.Helper()
+	user, _, err := app.authService.EnsureUser(context.Background(), string(role)+"@example.com", "user password", role)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := app.authService.GenerateToken(user, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// do serves a request signed in as the admin, with headers given as
+// name, value pairs.
+func (app testApp) do(method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+app.token)
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		r.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	app.router.ServeHTTP(w, r)
+	return w
+}
+
+// personCount counts the live persons stored.
+func (app testApp) personCount(t *testing.T) int {
+	t.Helper()
+	count, err := app.persons.CountPersons(context.Background(), domain.PersonFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
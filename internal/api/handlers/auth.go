@@ -2,25 +2,29 @@ package handlers
 
 import (
 	"encoding/json"
+	"log/slog"
 	"net/http"
-	"os"
 
+	"github.com/google/uuid"
 	"github.com/izymalhaw/go-crud/yishakterefe/internal/api/dto"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
 	"github.com/izymalhaw/go-crud/yishakterefe/internal/services/auth"
 	"github.com/izymalhaw/go-crud/yishakterefe/internal/util"
 )
 
 type AuthHandler struct {
-	authService   *auth.Service
-	adminEmail    string
-	adminPassword string
+	authService *auth.Service
+	trustProxy  bool
+	logger      *slog.Logger
 }
 
-func NewAuthHandler(authService *auth.Service) *AuthHandler {
+// NewAuthHandler builds the auth handlers. trustProxy makes login throttling
+// key clients on X-Forwarded-For instead of the connection address.
+func NewAuthHandler(authService *auth.Service, trustProxy bool, logger *slog.Logger) *AuthHandler {
 	return &AuthHandler{
-		authService:   authService,
-		adminEmail:    os.Getenv("ADMIN_EMAIL"),
-		adminPassword: os.Getenv("ADMIN_PASSWORD"),
+		authService: authService,
+		trustProxy:  trustProxy,
+		logger:      logger,
 	}
 }
 
@@ -28,8 +32,8 @@ func (h *AuthHandler) Login() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		//  Parse request body
 		var req dto.LoginRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			util.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		if err := util.DecodeJSON(w, r, &req); err != nil {
+			util.WriteDomainError(w, err, "Invalid request body")
 			return
 		}
 
@@ -39,20 +43,197 @@ func (h *AuthHandler) Login() http.HandlerFunc {
 			return
 		}
 
-		// Verify credentials
-		if req.Email != h.adminEmail || req.Password != h.adminPassword {
-			util.WriteErrorResponse(w, http.StatusUnauthorized, "Invalid credentials")
+		// Verify credentials, subject to the lockout policy
+		user, err := h.authService.Login(r.Context(), req.Email, req.Password, util.ClientIP(r, h.trustProxy))
+		if err != nil {
+			h.writeError(w, err, "Failed to verify credentials")
+			return
+		}
+
+		// Users with a second factor get a challenge instead of tokens
+		challenge, err := h.authService.MFAChallenge(r.Context(), user)
+		if err != nil {
+			h.writeError(w, err, "Failed to verify credentials")
+			return
+		}
+		if challenge != "" {
+			util.WriteSuccessResponse(w, dto.MFAChallengeResponse{
+				MFARequired: true,
+				MFAToken:    challenge,
+				ExpiresIn:   int64(h.authService.MFAChallengeTTL().Seconds()),
+			}, "Two-factor authentication required")
 			return
 		}
 
-		//  Generate JWT token
-		token, err := h.authService.GenerateToken(os.Getenv("ADMIN_EMAIL"))
+		//  Generate access and refresh tokens
+		pair, err := h.authService.IssueTokens(r.Context(), user, false)
 		if err != nil {
-			util.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to generate token")
+			h.writeError(w, err, "Failed to generate token")
 			return
 		}
 
 		// Return successful response
-		util.WriteSuccessResponse(w, dto.LoginResponse{Token: token}, "Login successful")
+		util.WriteSuccessResponse(w, tokenResponse(pair), "Login successful")
+	}
+}
+
+func (h *AuthHandler) Refresh() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.RefreshTokenRequest
+		if err := util.DecodeJSON(w, r, &req); err != nil {
+			util.WriteDomainError(w, err, "Invalid request body")
+			return
+		}
+		if req.RefreshToken == "" {
+			util.WriteErrorResponse(w, http.StatusBadRequest, "refresh_token is required")
+			return
+		}
+
+		pair, err := h.authService.Refresh(r.Context(), req.RefreshToken)
+		if err != nil {
+			h.writeError(w, err, "Failed to refresh token")
+			return
+		}
+
+		util.WriteSuccessResponse(w, tokenResponse(pair), "Token refreshed")
 	}
-}
\ No newline at end of file
+}
+
+func (h *AuthHandler) Logout() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.RefreshTokenRequest
+		if err := util.DecodeJSON(w, r, &req); err != nil {
+			util.WriteDomainError(w, err, "Invalid request body")
+			return
+		}
+		if req.RefreshToken == "" {
+			util.WriteErrorResponse(w, http.StatusBadRequest, "refresh_token is required")
+			return
+		}
+
+		if err := h.authService.Logout(r.Context(), req.RefreshToken); err != nil {
+			h.writeError(w, err, "Failed to log out")
+			return
+		}
+
+		util.WriteSuccessResponse(w, nil, "Logged out")
+	}
+}
+
+func (h *AuthHandler) Register() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.RegisterRequest
+		if err := util.DecodeJSON(w, r, &req); err != nil {
+			util.WriteDomainError(w, err, "Invalid request body")
+			return
+		}
+
+		user, err := h.authService.Register(r.Context(), req.Email, req.Password)
+		if err != nil {
+			h.writeError(w, err, "Failed to register user")
+			return
+		}
+
+		util.WriteSuccessResponse(w, dto.UserResponse{
+			Id:        user.Id,
+			Email:     user.Email,
+			Role:      string(user.Role),
+			CreatedAt: user.CreatedAt,
+		}, "Registration successful")
+	}
+}
+
+func (h *AuthHandler) ChangePassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := sessionUserID(w, r)
+		if !ok {
+			return
+		}
+		principal, _ := PrincipalFromContext(r.Context())
+
+		var req dto.ChangePasswordRequest
+		if err := util.DecodeJSON(w, r, &req); err != nil {
+			util.WriteDomainError(w, err, "Invalid request body")
+			return
+		}
+
+		pair, err := h.authService.ChangePassword(r.Context(), userID, req.CurrentPassword, req.NewPassword,
+			util.ClientIP(r, h.trustProxy), principal.MFA)
+		if err != nil {
+			h.writeError(w, err, "Failed to change password")
+			return
+		}
+
+		util.WriteSuccessResponse(w, tokenResponse(pair), "Password changed successfully")
+	}
+}
+
+func (h *AuthHandler) SetRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := uuid.Parse(r.PathValue("userId"))
+		if err != nil {
+			util.WriteErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+			return
+		}
+
+		var req dto.SetRoleRequest
+		if err := util.DecodeJSON(w, r, &req); err != nil {
+			util.WriteDomainError(w, err, "Invalid request body")
+			return
+		}
+
+		if err := h.authService.SetRole(r.Context(), userID, domain.Role(req.Role)); err != nil {
+			h.writeError(w, err, "Failed to update role")
+			return
+		}
+
+		util.WriteSuccessResponse(w, nil, "Role updated")
+	}
+}
+
+func (h *AuthHandler) RevokeTokens() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.RevokeTokensRequest
+		if err := util.DecodeJSON(w, r, &req); err != nil {
+			util.WriteDomainError(w, err, "Invalid request body")
+			return
+		}
+		if (req.JTI == "") == (req.UserID == nil) {
+			util.WriteErrorResponse(w, http.StatusBadRequest, auth.ErrRevocationTarget.Error())
+			return
+		}
+
+		var err error
+		if req.UserID != nil {
+			err = h.authService.RevokeUser(r.Context(), *req.UserID)
+		} else {
+			err = h.authService.RevokeToken(r.Context(), req.JTI)
+		}
+		if err != nil {
+			h.writeError(w, err, "Failed to revoke tokens")
+			return
+		}
+
+		util.WriteSuccessResponse(w, nil, "Tokens revoked")
+	}
+}
+
+// JWKS publishes the public signing keys so other services can verify our
+// tokens. It is a bare RFC 7517 key set rather than an APIResponse.
+func (h *AuthHandler) JWKS() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(h.authService.JWKS())
+	}
+}
+
+func tokenResponse(pair auth.TokenPair) dto.LoginResponse {
+	return dto.LoginResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(pair.ExpiresIn.Seconds()),
+	}
+}
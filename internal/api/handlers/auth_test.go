@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/ports"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/repository"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/services/auth"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/util"
+)
+
+// brokenUserStore fails every lookup by email.
+type brokenUserStore struct {
+	*repository.InMemoryUserStore
+}
+
+func (brokenUserStore) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestAuthErrors(t *testing.T) {
+	const (
+		login    = `{"email":"al@example.com","password":"al password"}`
+		register = `{"email":"al@example.com","password":"al password"}`
+	)
+	tests := []struct {
+		name       string
+		broken     bool
+		handler    func(h *AuthHandler) http.HandlerFunc
+		body       string
+		wantStatus int
+		wantLogged bool
+	}{
+		{name: "register bad email", handler: (*AuthHandler).Register, body: `{"email":"al","password":"al password"}`, wantStatus: http.StatusBadRequest},
+		{name: "register short password", handler: (*AuthHandler).Register, body: `{"email":"bo@example.com","password":"short"}`, wantStatus: http.StatusBadRequest},
+		{name: "register taken email", handler: (*AuthHandler).Register, body: register, wantStatus: http.StatusConflict},
+		{name: "login wrong password", handler: (*AuthHandler).Login, body: `{"email":"al@example.com","password":"wrong password"}`, wantStatus: http.StatusUnauthorized},
+		{name: "login", handler: (*AuthHandler).Login, body: login, wantStatus: http.StatusOK},
+		{name: "login store failure", broken: true, handler: (*AuthHandler).Login, body: login, wantStatus: http.StatusInternalServerError, wantLogged: true},
+		{name: "refresh unknown token", handler: (*AuthHandler).Refresh, body: `{"refresh_token":"unknown"}`, wantStatus: http.StatusUnauthorized},
+		{name: "mfa login bad token", handler: (*AuthHandler).LoginMFA, body: `{"mfa_token":"bad","code":"123456"}`, wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := auth.GenerateKeySet()
+			if err != nil {
+				t.Fatal(err)
+			}
+			store := repository.NewInMemoryUserStore()
+			var userRepo ports.UserRepository = store
+			if tt.broken {
+				userRepo = brokenUserStore{store}
+			}
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&logs, nil))
+			authService := auth.NewAuthService(
+				auth.Config{Keys: keys, AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour, Logger: logger},
+				userRepo,
+				repository.NewInMemoryRefreshTokenStore(),
+				repository.NewInMemoryAPIKeyStore(),
+				repository.NewInMemoryRevocationStore(),
+				repository.NewInMemoryLoginAttemptStore(),
+				repository.NewInMemoryMFAStore(),
+			)
+			if _, err := authService.Register(context.Background(), "al@example.com", "al password"); err != nil {
+				t.Fatal(err)
+			}
+			h := NewAuthHandler(authService, false, logger)
+
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			util.RequestID(tt.handler(h)).ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			requestID := w.Header().Get(util.RequestIDHeader)
+			if logged := strings.Contains(logs.String(), "request_id="+requestID); logged != tt.wantLogged {
+				t.Errorf("logged with the request ID = %v, want %v: %s", logged, tt.wantLogged, logs.String())
+			}
+		})
+	}
+}
@@ -2,18 +2,24 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"strings"
 
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
 	"github.com/izymalhaw/go-crud/yishakterefe/internal/services/auth"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/util"
 )
 
 func (app *Server) enableCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
 		w.Header().Set("Access-Control-Allow-Origin", ("*"))
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, If-Match, If-None-Match, X-Request-ID, Idempotency-Key")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, Link, Location, Content-Disposition, X-Request-ID, Idempotent-Replayed")
 		w.Header().Set("Access-Control-Max-Age", "3600")
 
 		if r.Method == "OPTIONS" {
@@ -25,31 +31,120 @@ func (app *Server) enableCORS(next http.Handler) http.Handler {
 }
 type contextKey string
 
-const userIDKey contextKey = "userID"
+const principalKey contextKey = "principal"
 
-func AuthMiddleware(authService *auth.Service) func(http.Handler) http.Handler {
+// AuthMiddleware authenticates the caller with either a Bearer access token
+// or an API key (sent as "X-API-Key: <key>" or "Authorization: ApiKey <key>")
+// and stores the resulting principal in the request context. Rejected
+// credentials are answered with 401; failures to check them, such as an
+// unreachable database, are logged and answered with 500.
+func AuthMiddleware(authService *auth.Service, logger *slog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			tokenString := r.Header.Get("Authorization")
-			if tokenString == "" {
-				http.Error(w, `{"error": "authorization header required"}`, http.StatusUnauthorized)
+			credential, isAPIKey := credentialFromRequest(r)
+			if credential == "" {
+				util.WriteErrorResponse(w, http.StatusUnauthorized, "Authorization header required")
 				return
 			}
 
-			// Remove "Bearer " prefix if present
-			if len(tokenString) > 7 && strings.HasPrefix(tokenString, "Bearer ") {
-				tokenString = tokenString[7:]
+			var (
+				principal auth.Principal
+				err       error
+			)
+			if isAPIKey {
+				principal, err = authService.AuthenticateAPIKey(r.Context(), credential)
+			} else {
+				principal, err = authService.ValidateToken(r.Context(), credential)
+			}
+			if errors.Is(err, domain.ErrUnauthorized) {
+				util.WriteErrorResponse(w, http.StatusUnauthorized, "Invalid token")
+				return
 			}
-
-			userID, err := authService.ValidateToken(tokenString)
 			if err != nil {
-				http.Error(w, `{"error": "invalid token"}`, http.StatusUnauthorized)
+				logger.Error("Failed to authenticate request", "error", err, "request_id", w.Header().Get(util.RequestIDHeader))
+				util.WriteDomainError(w, err, "Failed to authenticate request")
 				return
 			}
 
-			// Store userID in context
-			ctx := context.WithValue(r.Context(), userIDKey, userID)
+			// Store principal in context
+			ctx := context.WithValue(r.Context(), principalKey, principal)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// credentialFromRequest extracts the presented credential and whether it is
+// an API key.
+func credentialFromRequest(r *http.Request) (string, bool) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key, true
+	}
+
+	tokenString := r.Header.Get("Authorization")
+	if key, ok := strings.CutPrefix(tokenString, "ApiKey "); ok {
+		return key, true
+	}
+
+	// Remove "Bearer " prefix if present
+	if len(tokenString) > 7 && strings.HasPrefix(tokenString, "Bearer ") {
+		tokenString = tokenString[7:]
+	}
+	return tokenString, auth.IsAPIKey(tokenString)
+}
+
+// RequirePermission rejects requests whose principal lacks perm. It must be
+// applied after AuthMiddleware.
+func RequirePermission(perm domain.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				util.WriteErrorResponse(w, http.StatusUnauthorized, "Authentication required")
+				return
+			}
+
+			if !principal.HasPermission(perm) {
+				reason := fmt.Sprintf("Forbidden: role %q lacks permission %q", principal.Role, perm)
+				if principal.APIKeyID != "" {
+					reason = fmt.Sprintf("Forbidden: API key lacks scope %q", perm)
+				}
+				util.WriteErrorResponse(w, http.StatusForbidden, reason)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSecondFactor rejects callers that authService requires to have
+// signed in with a second factor but did not. It must be applied after
+// AuthMiddleware.
+func RequireSecondFactor(authService *auth.Service) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := PrincipalFromContext(r.Context())
+			if !ok {
+				util.WriteErrorResponse(w, http.StatusUnauthorized, "Authentication required")
+				return
+			}
+
+			if authService.MFARequired(principal) {
+				util.WriteErrorResponse(w, http.StatusForbidden, "Forbidden: admins must sign in with two-factor authentication")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// PrincipalFromContext returns the caller stored by AuthMiddleware.
+func PrincipalFromContext(ctx context.Context) (auth.Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(auth.Principal)
+	return principal, ok
+}
+
+// UserIDFromContext returns the token subject stored by AuthMiddleware.
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	principal, ok := PrincipalFromContext(ctx)
+	return principal.Subject, ok
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+func TestRequirePermission(t *testing.T) {
+	person := "/api/v1/person/" + uuid.NewString()
+	routes := []struct {
+		method string
+		path   string
+		body   string
+		// allowed lists the roles holding the route's permission.
+		allowed []domain.Role
+	}{
+		{method: http.MethodGet, path: "/api/v1/person", allowed: []domain.Role{domain.RoleAdmin, domain.RoleEditor, domain.RoleViewer}},
+		{method: http.MethodPost, path: "/api/v1/person/create", body: `{"name":"Al","age":30,"hobbies":[]}`, allowed: []domain.Role{domain.RoleAdmin, domain.RoleEditor}},
+		{method: http.MethodDelete, path: person, allowed: []domain.Role{domain.RoleAdmin, domain.RoleEditor}},
+		{method: http.MethodPost, path: person + "/restore", allowed: []domain.Role{domain.RoleAdmin}},
+		{method: http.MethodGet, path: "/api/v1/admin/persons/deleted", allowed: []domain.Role{domain.RoleAdmin}},
+		{method: http.MethodPut, path: "/auth/users/" + uuid.NewString() + "/role", body: `{"role":"viewer"}`, allowed: []domain.Role{domain.RoleAdmin}},
+		{method: http.MethodPost, path: "/api/v1/admin/tokens/revoke", body: `{"jti":"x"}`, allowed: []domain.Role{domain.RoleAdmin}},
+	}
+	app := newTestApp(t)
+	for _, role := range []domain.Role{domain.RoleAdmin, domain.RoleEditor, domain.RoleViewer} {
+		token := app.tokenFor(t, role)
+		for _, route := range routes {
+			t.Run(string(role)+" "+route.method+" "+route.path, func(t *testing.T) {
+				r := httptest.NewRequest(route.method, route.path, strings.NewReader(route.body))
+				r.Header.Set("Authorization", "Bearer "+token)
+				if route.body != "" {
+					r.Header.Set("Content-Type", "application/json")
+				}
+				w := httptest.NewRecorder()
+				app.router.ServeHTTP(w, r)
+
+				wantForbidden := !slices.Contains(route.allowed, role)
+				if forbidden := w.Code == http.StatusForbidden; forbidden != wantForbidden {
+					t.Errorf("status %d, want forbidden = %v: %s", w.Code, wantForbidden, w.Body)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/services/auth"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/util"
+)
+
+// writeError answers with the status of err's domain kind. Internal
+// failures are logged with the request ID and answered with message.
+func (s *Server) writeError(w http.ResponseWriter, err error, message string) {
+	writeError(s.logger, w, err, message)
+}
+
+// writeError is Server.writeError for the auth handlers, which also answer
+// throttled logins with 429.
+func (h *AuthHandler) writeError(w http.ResponseWriter, err error, message string) {
+	var throttled *auth.LoginThrottledError
+	if errors.As(err, &throttled) {
+		writeThrottled(w, throttled)
+		return
+	}
+	writeError(h.logger, w, err, message)
+}
+
+func writeError(logger *slog.Logger, w http.ResponseWriter, err error, message string) {
+	if util.ErrorStatus(err) == http.StatusInternalServerError {
+		logger.Error(message, "error", err, "request_id", w.Header().Get(util.RequestIDHeader))
+	}
+	util.WriteDomainError(w, err, message)
+}
+
+// writeThrottled answers 429 with a Retry-After header.
+func writeThrottled(w http.ResponseWriter, throttled *auth.LoginThrottledError) {
+	w.Header().Set("Retry-After", strconv.Itoa(throttled.RetryAfterSeconds()))
+	util.WriteErrorResponse(w, http.StatusTooManyRequests, throttled.Error())
+}
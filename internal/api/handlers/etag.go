@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/util"
+)
+
+// personETag is the strong entity tag of a person version.
+func personETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// etagMatches reports whether header, an If-Match or If-None-Match list,
+// is "*" or lists etag. Weak comparison, used for If-None-Match, ignores
+// W/ prefixes; strong comparison never matches a weak tag.
+func etagMatches(header, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// expectedVersion resolves the If-Match header of a write on person id to
+// the version the write must find, 0 when there is no precondition. If the
+// precondition already fails it writes 412 and returns false.
+func (s *Server) expectedVersion(w http.ResponseWriter, r *http.Request, id uuid.UUID) (int64, bool) {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return 0, true
+	}
+
+	current, err := s.PersonService.GetPerson(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrPersonNotFound) {
+			util.WriteErrorResponse(w, http.StatusPreconditionFailed, "Precondition failed: person does not exist")
+			return 0, false
+		}
+		s.writeError(w, err, "Failed to retrieve person")
+		return 0, false
+	}
+	if !etagMatches(ifMatch, personETag(current.Version), false) {
+		writePreconditionFailed(w, current.Version)
+		return 0, false
+	}
+	// The write stays conditional on this version, so a change made after
+	// the lookup still fails it.
+	return current.Version, true
+}
+
+// writePreconditionFailed reports a stale If-Match along with the current
+// entity tag.
+func writePreconditionFailed(w http.ResponseWriter, version int64) {
+	if version != 0 {
+		w.Header().Set("ETag", personETag(version))
+	}
+	util.WriteErrorResponse(w, http.StatusPreconditionFailed, "Precondition failed: person has been modified")
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import "testing"
+
+func TestEtagMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		etag   string
+		weak   bool
+		want   bool
+	}{
+		{name: "same tag", header: `"3"`, etag: `"3"`, want: true},
+		{name: "other tag", header: `"2"`, etag: `"3"`},
+		{name: "wildcard", header: "*", etag: `"3"`, want: true},
+		{name: "wildcard with spaces", header: " * ", etag: `"3"`, want: true},
+		{name: "listed among others", header: `"1", "3" ,"5"`, etag: `"3"`, want: true},
+		{name: "not listed", header: `"1", "2"`, etag: `"3"`},
+		{name: "unquoted", header: `3`, etag: `"3"`},
+		{name: "empty header", header: "", etag: `"3"`},
+		{name: "weak tag, strong comparison", header: `W/"3"`, etag: `"3"`},
+		{name: "weak tag, weak comparison", header: `W/"3"`, etag: `"3"`, weak: true, want: true},
+		{name: "weak list, weak comparison", header: `W/"1", W/"3"`, etag: `"3"`, weak: true, want: true},
+		{name: "strong tag, weak comparison", header: `"3"`, etag: `"3"`, weak: true, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := etagMatches(tt.header, tt.etag, tt.weak); got != tt.want {
+				t.Errorf("etagMatches(%q, %q, %v) = %v, want %v", tt.header, tt.etag, tt.weak, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPersonETag(t *testing.T) {
+	if got := personETag(42); got != `"42"` {
+		t.Errorf(`personETag(42) = %s, want "42"`, got)
+	}
+}
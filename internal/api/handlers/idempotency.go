@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/util"
+)
+
+// maxIdempotencyKeyLength bounds the Idempotency-Key header.
+const maxIdempotencyKeyLength = 255
+
+// idempotent makes h safe to retry with an Idempotency-Key header. The first
+// response to a key is stored and sent again, with Idempotent-Replayed set,
+// for later requests with the same key and body; a retry arriving while the
+// first request is in progress gets 409. Server errors and 406 are not
+// stored, so their retries run again: a 406 depends on the Accept header,
+// which the key does not cover. Requests without the header run as usual. It
+// must be applied after AuthMiddleware.
+func (server *Server) idempotent(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if !validIdempotencyKey(key) {
+			util.WriteErrorResponse(w, http.StatusBadRequest, "Idempotency-Key must be 1 to 255 visible ASCII characters")
+			return
+		}
+		principal, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			util.WriteErrorResponse(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		// Bodies over the limit are hashed by their head; the handler
+		// rejects them anyway.
+		body, err := io.ReadAll(io.LimitReader(r.Body, util.MaxRequestBytes+1))
+		if err != nil {
+			util.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+
+		replay, err := server.idempotency.Begin(r.Context(), principal.Subject, key, requestHash(r, body))
+		if err != nil {
+			server.writeError(w, err, "Failed to check idempotency key")
+			return
+		}
+		if replay != nil {
+			w.Header().Set("Content-Type", replay.ContentType)
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(replay.StatusCode)
+			w.Write(replay.Body)
+			return
+		}
+
+		// The outcome is stored even if the client has gone, so that its
+		// retry finds it.
+		ctx := context.WithoutCancel(r.Context())
+		recorder := &responseRecorder{ResponseWriter: w}
+		completed := false
+		defer func() {
+			if !completed {
+				// The handler panicked.
+				server.idempotency.Release(ctx, principal.Subject, key)
+			}
+		}()
+		h.ServeHTTP(recorder, r)
+		completed = true
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+
+		if recorder.status >= http.StatusInternalServerError || recorder.status == http.StatusNotAcceptable {
+			err = server.idempotency.Release(ctx, principal.Subject, key)
+		} else {
+			err = server.idempotency.Complete(ctx, principal.Subject, key,
+				recorder.status, recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+		}
+		if err != nil {
+			server.logger.Error("Failed to store idempotent response", "error", err, "request_id", w.Header().Get(util.RequestIDHeader))
+		}
+	})
+}
+
+// validIdempotencyKey accepts keys of visible ASCII characters.
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestHash identifies a request by what a retry repeats: its method,
+// path, Content-Type and body.
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	for _, part := range []string{r.Method, r.URL.Path, r.Header.Get("Content-Type")} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder passes a response through while keeping its status and
+// body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
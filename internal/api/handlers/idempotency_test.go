@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/repository"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/services/auth"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/services/idempotency"
+)
+
+// countingHandler answers with status and the number of requests it has
+// served, so that replays can be told from new runs.
+type countingHandler struct {
+	status int
+	calls  int
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	io.Copy(io.Discard, r.Body)
+	h.calls++
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(h.status)
+	io.WriteString(w, strings.Repeat("x", h.calls))
+}
+
+func TestIdempotent(t *testing.T) {
+	type request struct {
+		principal string
+		key       string
+		body      string
+		// status, wantBody and replayed describe the response.
+		status   int
+		wantBody string
+		replayed bool
+	}
+	tests := []struct {
+		name          string
+		handlerStatus int
+		requests      []request
+	}{
+		{
+			name:          "retry is replayed",
+			handlerStatus: http.StatusOK,
+			requests: []request{
+				{principal: "alice", key: "k", body: `{"a":1}`, status: http.StatusOK, wantBody: "x"},
+				{principal: "alice", key: "k", body: `{"a":1}`, status: http.StatusOK, wantBody: "x", replayed: true},
+			},
+		},
+		{
+			name:          "client errors are replayed",
+			handlerStatus: http.StatusUnprocessableEntity,
+			requests: []request{
+				{principal: "alice", key: "k", body: `{}`, status: http.StatusUnprocessableEntity, wantBody: "x"},
+				{principal: "alice", key: "k", body: `{}`, status: http.StatusUnprocessableEntity, wantBody: "x", replayed: true},
+			},
+		},
+		{
+			name:          "server errors run again",
+			handlerStatus: http.StatusServiceUnavailable,
+			requests: []request{
+				{principal: "alice", key: "k", body: `{}`, status: http.StatusServiceUnavailable, wantBody: "x"},
+				{principal: "alice", key: "k", body: `{}`, status: http.StatusServiceUnavailable, wantBody: "xx"},
+			},
+		},
+		{
+			name:          "not acceptable runs again",
+			handlerStatus: http.StatusNotAcceptable,
+			requests: []request{
+				{principal: "alice", key: "k", body: `{}`, status: http.StatusNotAcceptable, wantBody: "x"},
+				{principal: "alice", key: "k", body: `{}`, status: http.StatusNotAcceptable, wantBody: "xx"},
+			},
+		},
+		{
+			name:          "other body",
+			handlerStatus: http.StatusOK,
+			requests: []request{
+				{principal: "alice", key: "k", body: `{"a":1}`, status: http.StatusOK, wantBody: "x"},
+				{principal: "alice", key: "k", body: `{"a":2}`, status: http.StatusUnprocessableEntity},
+			},
+		},
+		{
+			name:          "keys are per caller",
+			handlerStatus: http.StatusOK,
+			requests: []request{
+				{principal: "alice", key: "k", body: `{}`, status: http.StatusOK, wantBody: "x"},
+				{principal: "bob", key: "k", body: `{}`, status: http.StatusOK, wantBody: "xx"},
+			},
+		},
+		{
+			name:          "no key",
+			handlerStatus: http.StatusOK,
+			requests: []request{
+				{principal: "alice", body: `{}`, status: http.StatusOK, wantBody: "x"},
+				{principal: "alice", body: `{}`, status: http.StatusOK, wantBody: "xx"},
+			},
+		},
+		{
+			name:          "invalid key",
+			handlerStatus: http.StatusOK,
+			requests: []request{
+				{principal: "alice", key: "two words", body: `{}`, status: http.StatusBadRequest},
+				{principal: "alice", key: strings.Repeat("k", 256), body: `{}`, status: http.StatusBadRequest},
+			},
+		},
+		{
+			name:          "anonymous",
+			handlerStatus: http.StatusOK,
+			requests: []request{
+				{key: "k", body: `{}`, status: http.StatusUnauthorized},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &Server{
+				idempotency: idempotency.NewIdempotencyService(repository.NewInMemoryIdempotencyStore(), time.Hour),
+				logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+			}
+			h := &countingHandler{status: tt.handlerStatus}
+			handler := server.idempotent(h)
+
+			for i, req := range tt.requests {
+				r := httptest.NewRequest(http.MethodPost, "/api/v1/person/create", strings.NewReader(req.body))
+				r.Header.Set("Content-Type", "application/json")
+				if req.key != "" {
+					r.Header.Set("Idempotency-Key", req.key)
+				}
+				if req.principal != "" {
+					r = r.WithContext(context.WithValue(r.Context(), principalKey, auth.Principal{Subject: req.principal}))
+				}
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+
+				if w.Code != req.status {
+					t.Fatalf("request %d: status %d, want %d: %s", i, w.Code, req.status, w.Body)
+				}
+				if req.wantBody != "" && w.Body.String() != req.wantBody {
+					t.Errorf("request %d: body %q, want %q", i, w.Body, req.wantBody)
+				}
+				if replayed := w.Header().Get("Idempotent-Replayed") == "true"; replayed != req.replayed {
+					t.Errorf("request %d: replayed = %v, want %v", i, replayed, req.replayed)
+				}
+				if req.replayed && w.Header().Get("Content-Type") != "text/plain" {
+					t.Errorf("request %d: replayed Content-Type %q", i, w.Header().Get("Content-Type"))
+				}
+			}
+		})
+	}
+}
+
+func TestIdempotentRetryWithOtherAccept(t *testing.T) {
+	app := newTestApp(t)
+	const body = `{"name":"Al","age":30,"hobbies":[]}`
+
+	if w := app.do(http.MethodPost, "/api/v1/person/create", body, "Idempotency-Key", "k", "Accept", "text/csv"); w.Code != http.StatusNotAcceptable {
+		t.Fatalf("status %d, want 406: %s", w.Code, w.Body)
+	}
+	w := app.do(http.MethodPost, "/api/v1/person/create", body, "Idempotency-Key", "k", "Accept", "application/json")
+	if w.Code != http.StatusOK || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("retry: status %d, replayed %q: %s", w.Code, w.Header().Get("Idempotent-Replayed"), w.Body)
+	}
+	if got := app.personCount(t); got != 1 {
+		t.Errorf("%d persons stored, want 1", got)
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/api/dto"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/util"
+)
+
+// LoginMFA completes a login that was answered with an MFA challenge.
+func (h *AuthHandler) LoginMFA() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req dto.MFALoginRequest
+		if err := util.DecodeJSON(w, r, &req); err != nil {
+			util.WriteDomainError(w, err, "Invalid request body")
+			return
+		}
+		if req.MFAToken == "" || req.Code == "" {
+			util.WriteErrorResponse(w, http.StatusBadRequest, "mfa_token and code are required")
+			return
+		}
+
+		pair, err := h.authService.CompleteMFALogin(r.Context(), req.MFAToken, req.Code)
+		if err != nil {
+			h.writeError(w, err, "Failed to verify credentials")
+			return
+		}
+
+		util.WriteSuccessResponse(w, tokenResponse(pair), "Login successful")
+	}
+}
+
+func (h *AuthHandler) EnrollTOTP() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := sessionUserID(w, r)
+		if !ok {
+			return
+		}
+
+		setup, err := h.authService.EnrollTOTP(r.Context(), userID)
+		if err != nil {
+			h.writeError(w, err, "Failed to manage two-factor authentication")
+			return
+		}
+
+		util.WriteSuccessResponse(w, dto.TOTPEnrollmentResponse{
+			Secret:     setup.Secret,
+			OTPAuthURI: setup.URI,
+		}, "Add the secret to your authenticator app, then confirm with a code")
+	}
+}
+
+func (h *AuthHandler) ConfirmTOTP() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := sessionUserID(w, r)
+		if !ok {
+			return
+		}
+
+		var req dto.MFACodeRequest
+		if err := util.DecodeJSON(w, r, &req); err != nil {
+			util.WriteDomainError(w, err, "Invalid request body")
+			return
+		}
+
+		codes, err := h.authService.ConfirmTOTP(r.Context(), userID, req.Code)
+		if err != nil {
+			h.writeError(w, err, "Failed to manage two-factor authentication")
+			return
+		}
+
+		util.WriteSuccessResponse(w, dto.RecoveryCodesResponse{RecoveryCodes: codes},
+			"Two-factor authentication enabled; store the recovery codes now, they will not be shown again")
+	}
+}
+
+func (h *AuthHandler) DisableTOTP() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := sessionUserID(w, r)
+		if !ok {
+			return
+		}
+
+		var req dto.MFACodeRequest
+		if err := util.DecodeJSON(w, r, &req); err != nil {
+			util.WriteDomainError(w, err, "Invalid request body")
+			return
+		}
+
+		if err := h.authService.DisableTOTP(r.Context(), userID, req.Code); err != nil {
+			h.writeError(w, err, "Failed to manage two-factor authentication")
+			return
+		}
+
+		util.WriteSuccessResponse(w, nil, "Two-factor authentication disabled")
+	}
+}
+
+func (h *AuthHandler) RegenerateRecoveryCodes() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := sessionUserID(w, r)
+		if !ok {
+			return
+		}
+
+		var req dto.MFACodeRequest
+		if err := util.DecodeJSON(w, r, &req); err != nil {
+			util.WriteDomainError(w, err, "Invalid request body")
+			return
+		}
+
+		codes, err := h.authService.RegenerateRecoveryCodes(r.Context(), userID, req.Code)
+		if err != nil {
+			h.writeError(w, err, "Failed to manage two-factor authentication")
+			return
+		}
+
+		util.WriteSuccessResponse(w, dto.RecoveryCodesResponse{RecoveryCodes: codes},
+			"Recovery codes regenerated; store them now, they will not be shown again")
+	}
+}
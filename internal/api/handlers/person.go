@@ -1,49 +1,68 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/google/uuid"
 	_ "github.com/izymalhaw/go-crud/yishakterefe/docs"
 	"github.com/izymalhaw/go-crud/yishakterefe/internal/api/dto"
 	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	person_service "github.com/izymalhaw/go-crud/yishakterefe/internal/services/person"
 	"github.com/izymalhaw/go-crud/yishakterefe/internal/util"
 )
 
+// maxPatchBytes bounds the size of a patch document.
+const maxPatchBytes = 1 << 20
+
+// acceptPatch lists the patch media types PATCH accepts, for the
+// Accept-Patch header.
+const acceptPatch = string(person_service.MergePatch) + ", " + string(person_service.JSONPatch)
+
 // @Summary Create a new person
 // @Description This endpoint creates a new person entry.
 // @Tags person
 // @Accept json
+// @Accept xml
+// @Accept application/msgpack
 // @Produce json
+// @Produce xml
+// @Produce application/msgpack
 // @Param dto.PersonRequest body dto.PersonRequest true "Person Data"
+// @Param Idempotency-Key header string false "Replays the first response to retries with the same key and body"
 // @Success 200 {object} dto.PersonResponse
-// @Failure 400 {object} dto.PersonResponse
-// @Failure 500 {object} dto.PersonResponse
+// @Failure 400 {object} util.Problem
+// @Failure 406 {object} util.Problem
+// @Failure 409 {object} util.Problem
+// @Failure 413 {object} util.Problem
+// @Failure 415 {object} util.Problem
+// @Failure 422 {object} util.Problem
+// @Failure 500 {object} util.Problem
 // @Router /api/v1/person/create [post]
 func (s *Server) CreatePerson() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req dto.PersonRequest
 
-		err := json.NewDecoder(r.Body).Decode(&req)
-		if err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		if err := util.DecodeRequest(w, r, &req); err != nil {
+			s.writeError(w, err, "Invalid request body")
 			return
 		}
 		defer r.Body.Close()
 		ctx := r.Context()
 		id := uuid.New()
-		err = s.PersonService.CreatePerson(ctx, domain.Person{
+		err := s.PersonService.CreatePerson(ctx, domain.Person{
 			Id:      id,
 			Name:    req.Name,
 			Age:     req.Age,
 			Hobbies: req.Hobbies,
 		})
 		if err != nil {
-			s.logger.Error("Error creating person: %v", err.Error(), "")
-			util.WriteErrorResponse(w, http.StatusInternalServerError, "")
+			s.writeError(w, err, "Failed to create person")
 			return
 		}
 		response := fmt.Sprintf("successfully created person with ID: %s", id.String())
@@ -56,13 +75,35 @@ func (s *Server) CreatePerson() http.HandlerFunc {
 // @Tags person
 // @Accept json
 // @Produce json
-// @Param limit query int false "Limit number of results"
+// @Produce xml
+// @Produce application/msgpack
+// @Produce text/csv
+// @Param limit query int false "Limit number of results, at most 100"
 // @Param offset query int false "Offset for pagination"
-// @Success 200 {array} dto.PersonResponse
-// @Failure 400 {object} dto.PersonResponse
-// @Failure 500 {object} dto.PersonResponse
+// @Param name query string false "Case-insensitive substring of the name"
+// @Param name_prefix query string false "Case-insensitive prefix of the name"
+// @Param min_age query int false "Minimum age, inclusive"
+// @Param max_age query int false "Maximum age, inclusive"
+// @Param hobby query []string false "Hobby the person must have; repeat to require several" collectionFormat(multi)
+// @Param q query string false "Free-text search; every word must prefix a word of the name or a hobby"
+// @Param sort query string false "Sort field" Enums(created_at, updated_at, name, age)
+// @Param order query string false "Sort direction" Enums(asc, desc)
+// @Param cursor query string false "Opaque cursor from a previous page's links; replaces offset"
+// @Success 200 {object} util.ListResponse{data=[]dto.PersonResponse}
+// @Header 200 {string} Link "RFC 8288 links to the first, previous and next pages"
+// @Failure 400 {object} util.Problem
+// @Failure 406 {object} util.Problem
+// @Failure 500 {object} util.Problem
 // @Router /api/v1/person [get]
 func (s *Server) GetPersons() http.HandlerFunc {
+	return s.listPersons(false)
+}
+
+// maxPageLimit caps the limit query parameter of person lists.
+const maxPageLimit = 100
+
+// listPersons serves a page of the live persons, or of the deleted ones.
+func (s *Server) listPersons(deleted bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -73,106 +114,252 @@ func (s *Server) GetPersons() http.HandlerFunc {
 		if err != nil || limit <= 0 {
 			limit = 10
 		}
+		limit = min(limit, maxPageLimit)
 
 		offset, err := strconv.Atoi(offsetStr)
 		if err != nil || offset < 0 {
 			offset = 0
 		}
 
-		data, err := s.PersonService.GetAllPersons(ctx, limit, offset)
+		query, err := personQueryFromRequest(r, limit, offset)
+		if err != nil {
+			util.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		query.Deleted = deleted
+
+		cursor := r.URL.Query().Get("cursor")
+		page, err := s.PersonService.ListPersons(ctx, query, cursor)
 		if err != nil {
-			s.logger.Error("Error getting persons: %v", err.Error(), "")
-			util.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve persons")
+			s.writeError(w, err, "Failed to retrieve persons")
 			return
 		}
 
-		var response []dto.PersonResponse
-		for _, person := range data {
+		total, err := s.PersonService.CountPersons(ctx, query.PersonFilter)
+		if err != nil {
+			s.writeError(w, err, "Failed to retrieve persons")
+			return
+		}
+
+		response := make([]dto.PersonResponse, 0, len(page.Persons))
+		for _, person := range page.Persons {
 			response = append(response, dto.PersonResponse{
-				Id:      person.Id,
-				Name:    person.Name,
-				Age:     person.Age,
-				Hobbies: person.Hobbies,
+				Id:        person.Id,
+				Name:      person.Name,
+				Age:       person.Age,
+				Hobbies:   person.Hobbies,
+				DeletedAt: person.DeletedAt,
 			})
 		}
 
-		util.WriteSuccessResponse(w, response, "Persons retrieved successfully")
+		meta := util.ListMeta{
+			Total: total,
+			Limit: query.Limit,
+			Links: util.PageLinks{
+				First: pageURL(r, ""),
+				Next:  pageLink(r, page.NextCursor),
+				Prev:  pageLink(r, page.PrevCursor),
+			},
+		}
+		if cursor != "" {
+			meta.Cursor = cursor
+		} else {
+			meta.Offset = &query.Offset
+		}
+		util.WriteListResponse(w, response, meta, "Persons retrieved successfully")
 	}
 }
 
-// @Summary Update a person
-// @Description This endpoint updates a person's details.
+// @Summary Create or update a person
+// @Description This endpoint stores a person under the given ID: it creates the person if the ID is unused and otherwise updates their details. Send the ETag from a previous read in If-Match to avoid overwriting someone else's change; If-Match never creates a person.
 // @Tags person
 // @Accept json
+// @Accept xml
+// @Accept application/msgpack
 // @Produce json
+// @Produce xml
+// @Produce application/msgpack
 // @Param personId path string true "Person ID"
+// @Param If-Match header string false "ETag the person must still have"
 // @Param dto.PersonRequest body dto.PersonRequest true "Person Data"
 // @Success 200 {object} dto.PersonResponse
-// @Failure 400 {object} dto.PersonResponse
-// @Failure 500 {object} dto.PersonResponse
+// @Success 201 {object} dto.PersonResponse
+// @Header 200 {string} ETag "Entity tag of the updated person"
+// @Header 201 {string} ETag "Entity tag of the created person"
+// @Header 201 {string} Location "URL of the created person"
+// @Failure 400 {object} util.Problem
+// @Failure 404 {object} util.Problem
+// @Failure 406 {object} util.Problem
+// @Failure 409 {object} util.Problem
+// @Failure 412 {object} util.Problem
+// @Failure 413 {object} util.Problem
+// @Failure 415 {object} util.Problem
+// @Failure 422 {object} util.Problem
+// @Failure 500 {object} util.Problem
 // @Router /api/v1/person/{personId} [put]
 func (s *Server) UpdatePerson() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		person_id := r.PathValue("personId")
-		if person_id == "" {
-			util.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request")
+		id, err := uuid.Parse(r.PathValue("personId"))
+		if err != nil {
+			util.WriteErrorResponse(w, http.StatusBadRequest, "Invalid person ID")
 			return
 		}
 		var req dto.PersonRequest
 
-		err := json.NewDecoder(r.Body).Decode(&req)
-		if err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		if err := util.DecodeRequest(w, r, &req); err != nil {
+			s.writeError(w, err, "Invalid request body")
 			return
 		}
 		defer r.Body.Close()
+
+		version, ok := s.expectedVersion(w, r, id)
+		if !ok {
+			return
+		}
 		ctx := r.Context()
-		err = s.PersonService.UpdatePerson(ctx, domain.Person{
-			Id:      uuid.MustParse(person_id),
+		person, created, err := s.PersonService.UpsertPerson(ctx, domain.Person{
+			Id:      id,
 			Name:    req.Name,
 			Age:     req.Age,
 			Hobbies: req.Hobbies,
+			Version: version,
 		})
 		if err != nil {
-			s.logger.Error("Error updating person: %v", err.Error(), "")
-			util.WriteErrorResponse(w, http.StatusInternalServerError, "")
+			if errors.Is(err, domain.ErrPersonVersionMismatch) {
+				writePreconditionFailed(w, 0)
+				return
+			}
+			s.writeError(w, err, "Failed to update person")
 			return
 		}
-		responseString := fmt.Sprintf("Successfully updated person with ID: %s", person_id)
 		responseData := dto.PersonResponse{
-			Id:      uuid.MustParse(person_id),
-			Name:    req.Name,
-			Age:     req.Age,
-			Hobbies: req.Hobbies,
+			Id:      person.Id,
+			Name:    person.Name,
+			Age:     person.Age,
+			Hobbies: person.Hobbies,
+		}
+		w.Header().Set("ETag", personETag(person.Version))
+		if created {
+			w.Header().Set("Location", "/api/v1/person/"+id.String())
+			util.WriteResponse(w, http.StatusCreated, true, responseData, fmt.Sprintf("Successfully created person with ID: %s", id))
+			return
 		}
-		util.WriteSuccessResponse(w, responseData, responseString)
+		util.WriteSuccessResponse(w, responseData, fmt.Sprintf("Successfully updated person with ID: %s", id))
+	}
+}
+
+// @Summary Partially update a person
+// @Description Applies a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) to a person. Only the fields the patch changes are written.
+// @Tags person
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Produce xml
+// @Produce application/msgpack
+// @Param personId path string true "Person ID"
+// @Param If-Match header string false "ETag the person must still have"
+// @Param patch body object true "Merge patch object or JSON Patch operation array"
+// @Success 200 {object} dto.PersonResponse
+// @Header 200 {string} ETag "Entity tag of the patched person"
+// @Failure 400 {object} util.Problem
+// @Failure 404 {object} util.Problem
+// @Failure 406 {object} util.Problem
+// @Failure 409 {object} util.Problem
+// @Failure 412 {object} util.Problem
+// @Failure 413 {object} util.Problem
+// @Failure 415 {object} util.Problem
+// @Failure 422 {object} util.Problem
+// @Failure 500 {object} util.Problem
+// @Router /api/v1/person/{personId} [patch]
+func (s *Server) PatchPerson() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("personId"))
+		if err != nil {
+			util.WriteErrorResponse(w, http.StatusBadRequest, "Invalid person ID")
+			return
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		patchType := person_service.PatchType(mediaType)
+		if patchType != person_service.MergePatch && patchType != person_service.JSONPatch {
+			w.Header().Set("Accept-Patch", acceptPatch)
+			util.WriteErrorResponse(w, http.StatusUnsupportedMediaType, "Content-Type must be one of "+acceptPatch)
+			return
+		}
+
+		patch, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPatchBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				util.WriteErrorResponse(w, http.StatusRequestEntityTooLarge, "Patch document too large")
+				return
+			}
+			util.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+
+		version, ok := s.expectedVersion(w, r, id)
+		if !ok {
+			return
+		}
+		person, err := s.PersonService.PatchPerson(r.Context(), id, version, patchType, patch)
+		if err != nil {
+			if errors.Is(err, domain.ErrPersonVersionMismatch) {
+				writePreconditionFailed(w, 0)
+				return
+			}
+			s.writeError(w, err, "Failed to update person")
+			return
+		}
+
+		w.Header().Set("ETag", personETag(person.Version))
+		util.WriteSuccessResponse(w, dto.PersonResponse{
+			Id:      person.Id,
+			Name:    person.Name,
+			Age:     person.Age,
+			Hobbies: person.Hobbies,
+		}, fmt.Sprintf("Successfully updated person with ID: %s", person.Id))
 	}
 }
 
 // @Summary Get Single person
-// @Description This endpoint retrieves a single person entry.
+// @Description This endpoint retrieves a single person entry. Its ETag can be sent back in If-None-Match to skip an unchanged body, or in If-Match to make a write conditional.
 // @Tags person
 // @Accept json
 // @Produce json
+// @Produce xml
+// @Produce application/msgpack
 // @Param personId path string true "Person ID"
+// @Param If-None-Match header string false "ETag of a cached copy"
 // @Success 200 {object} dto.PersonResponse
-// @Failure 400 {object} dto.PersonResponse
-// @Failure 500 {object} dto.PersonResponse
+// @Header 200 {string} ETag "Entity tag of the person"
+// @Success 304 "Not modified"
+// @Failure 400 {object} util.Problem
+// @Failure 404 {object} util.Problem
+// @Failure 406 {object} util.Problem
+// @Failure 500 {object} util.Problem
 // @Router /api/v1/person/{personId} [get]
 func (s *Server) GetPerson() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		person_id := r.PathValue("personId")
-		if person_id == "" {
-			util.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request")
+		id, err := uuid.Parse(r.PathValue("personId"))
+		if err != nil {
+			util.WriteErrorResponse(w, http.StatusBadRequest, "Invalid person ID")
 			return
 		}
 		ctx := r.Context()
-		data, err := s.PersonService.GetPerson(ctx, uuid.MustParse(person_id))
+		data, err := s.PersonService.GetPerson(ctx, id)
 		if err != nil {
-			s.logger.Error("Error getting person: %v", err.Error(), "")
-			util.WriteErrorResponse(w, http.StatusInternalServerError, "")
+			s.writeError(w, err, "Failed to retrieve person")
 			return
 		}
+
+		etag := personETag(data.Version)
+		w.Header().Set("ETag", etag)
+		if etagMatches(r.Header.Get("If-None-Match"), etag, true) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
 		responseData := dto.PersonResponse{
 			Id:      data.Id,
 			Name:    data.Name,
@@ -184,30 +371,103 @@ func (s *Server) GetPerson() http.HandlerFunc {
 }
 
 // @Summary Delete person
-// @Description This endpoint deletes a person entry.
+// @Description This endpoint deletes a person entry. The person can be restored until it is purged after the retention period.
 // @Tags person
 // @Accept json
 // @Produce json
+// @Produce xml
+// @Produce application/msgpack
 // @Param personId path string true "Person ID"
+// @Param If-Match header string false "ETag the person must still have"
 // @Success 200 {string} string "Successfully deleted person"
-// @Failure 400 {object} dto.PersonResponse
-// @Failure 500 {object} dto.PersonResponse
+// @Failure 400 {object} util.Problem
+// @Failure 404 {object} util.Problem
+// @Failure 406 {object} util.Problem
+// @Failure 412 {object} util.Problem
+// @Failure 500 {object} util.Problem
 // @Router /api/v1/person/{personId} [delete]
 func (s *Server) DeletePerson() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		person_id := r.PathValue("personId")
-		if person_id == "" {
-			util.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request")
+		id, err := uuid.Parse(r.PathValue("personId"))
+		if err != nil {
+			util.WriteErrorResponse(w, http.StatusBadRequest, "Invalid person ID")
+			return
+		}
+		version, ok := s.expectedVersion(w, r, id)
+		if !ok {
 			return
 		}
 		ctx := r.Context()
-		err := s.PersonService.DeletePerson(ctx, uuid.MustParse(person_id))
+		err = s.PersonService.DeletePerson(ctx, id, version)
 		if err != nil {
-			s.logger.Error("Error deleting person: %v", err.Error(), "")
-			util.WriteErrorResponse(w, http.StatusInternalServerError, "")
+			if errors.Is(err, domain.ErrPersonVersionMismatch) {
+				writePreconditionFailed(w, 0)
+				return
+			}
+			s.writeError(w, err, "Failed to delete person")
 			return
 		}
-		responseString := fmt.Sprintf("Successfully deleted person with ID: %s", person_id)
+		responseString := fmt.Sprintf("Successfully deleted person with ID: %s", id)
 		util.WriteSuccessResponse(w, nil, responseString)
 	}
 }
+
+// personQueryFromRequest reads the list filters and sort order from the
+// query string. Unknown sort fields are left for the service to reject.
+func personQueryFromRequest(r *http.Request, limit, offset int) (domain.PersonQuery, error) {
+	params := r.URL.Query()
+	query := domain.DefaultPersonQuery(limit, offset)
+
+	query.NameContains = params.Get("name")
+	query.NamePrefix = params.Get("name_prefix")
+	query.Hobbies = params["hobby"]
+	query.Search = params.Get("q")
+
+	for key, dst := range map[string]**int{"min_age": &query.MinAge, "max_age": &query.MaxAge} {
+		if value := params.Get(key); value != "" {
+			age, err := strconv.Atoi(value)
+			if err != nil {
+				return domain.PersonQuery{}, fmt.Errorf("%s must be an integer", key)
+			}
+			*dst = &age
+		}
+	}
+
+	if sort := params.Get("sort"); sort != "" {
+		query.SortBy = domain.PersonSortField(sort)
+		// An explicit field sorts ascending unless told otherwise.
+		query.SortDesc = false
+	}
+	switch params.Get("order") {
+	case "":
+	case "asc":
+		query.SortDesc = false
+	case "desc":
+		query.SortDesc = true
+	default:
+		return domain.PersonQuery{}, errors.New("order must be asc or desc")
+	}
+	return query, nil
+}
+
+// pageLink returns the URL of the page at cursor, or nothing when there is
+// no such page.
+func pageLink(r *http.Request, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+	return pageURL(r, cursor)
+}
+
+// pageURL returns the URL of the current request moved to cursor, or to the
+// first page when cursor is empty, keeping its filters and sort order.
+func pageURL(r *http.Request, cursor string) string {
+	params := r.URL.Query()
+	params.Del("offset")
+	params.Del("cursor")
+	if cursor != "" {
+		params.Set("cursor", cursor)
+	}
+	link := url.URL{Path: r.URL.Path, RawQuery: params.Encode()}
+	return link.String()
+}
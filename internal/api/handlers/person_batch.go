@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/api/dto"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/util"
+)
+
+// maxBatchBytes bounds the size of a batch request body.
+const maxBatchBytes = 16 << 20
+
+// @Summary Create persons in a batch
+// @Description Creates up to 5000 persons. In atomic mode (the default) either all of them are created or none is; in best_effort mode every valid person is created. The result of each person reports its ID and the status it would have had as a single request.
+// @Tags person
+// @Accept json
+// @Produce json
+// @Produce xml
+// @Produce application/msgpack
+// @Param mode query string false "Whether one failed person fails the batch" Enums(atomic, best_effort)
+// @Param persons body []dto.PersonRequest true "Persons"
+// @Success 200 {object} util.APIResponse{data=dto.PersonBatchResponse}
+// @Success 207 {object} util.APIResponse{data=dto.PersonBatchResponse}
+// @Failure 400 {object} util.Problem
+// @Failure 406 {object} util.Problem
+// @Failure 413 {object} util.Problem
+// @Failure 422 {object} util.Problem{data=dto.PersonBatchResponse}
+// @Failure 500 {object} util.Problem
+// @Router /api/v1/person/batch/create [post]
+func (s *Server) CreatePersons() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		atomic, ok := batchMode(w, r)
+		if !ok {
+			return
+		}
+		var req []dto.PersonRequest
+		if !decodeBatch(w, r, &req) {
+			return
+		}
+
+		persons := make([]domain.Person, len(req))
+		for i, item := range req {
+			persons[i] = domain.Person{
+				Name:    item.Name,
+				Age:     item.Age,
+				Hobbies: item.Hobbies,
+			}
+		}
+		results, err := s.PersonService.CreatePersons(r.Context(), persons, atomic)
+		s.writeBatchResults(w, results, err, http.StatusCreated, "created")
+	}
+}
+
+// @Summary Update persons in a batch
+// @Description Replaces up to 5000 persons, each only at its version when one is given. In atomic mode (the default) either all of them are updated or none is; in best_effort mode every person that can be is updated.
+// @Tags person
+// @Accept json
+// @Produce json
+// @Produce xml
+// @Produce application/msgpack
+// @Param mode query string false "Whether one failed person fails the batch" Enums(atomic, best_effort)
+// @Param persons body []dto.PersonBatchUpdateRequest true "Persons"
+// @Success 200 {object} util.APIResponse{data=dto.PersonBatchResponse}
+// @Success 207 {object} util.APIResponse{data=dto.PersonBatchResponse}
+// @Failure 400 {object} util.Problem
+// @Failure 406 {object} util.Problem
+// @Failure 413 {object} util.Problem
+// @Failure 422 {object} util.Problem{data=dto.PersonBatchResponse}
+// @Failure 500 {object} util.Problem
+// @Router /api/v1/person/batch/update [post]
+func (s *Server) UpdatePersons() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		atomic, ok := batchMode(w, r)
+		if !ok {
+			return
+		}
+		var req []dto.PersonBatchUpdateRequest
+		if !decodeBatch(w, r, &req) {
+			return
+		}
+
+		persons := make([]domain.Person, len(req))
+		for i, item := range req {
+			persons[i] = domain.Person{
+				Id:      item.Id,
+				Name:    item.Name,
+				Age:     item.Age,
+				Hobbies: item.Hobbies,
+				Version: item.Version,
+			}
+		}
+		results, err := s.PersonService.UpdatePersons(r.Context(), persons, atomic)
+		s.writeBatchResults(w, results, err, http.StatusOK, "updated")
+	}
+}
+
+// @Summary Delete persons in a batch
+// @Description Deletes up to 5000 persons, each only at its version when one is given. In atomic mode (the default) either all of them are deleted or none is; in best_effort mode every person that can be is deleted.
+// @Tags person
+// @Accept json
+// @Produce json
+// @Produce xml
+// @Produce application/msgpack
+// @Param mode query string false "Whether one failed person fails the batch" Enums(atomic, best_effort)
+// @Param persons body []dto.PersonBatchDeleteRequest true "Persons"
+// @Success 200 {object} util.APIResponse{data=dto.PersonBatchResponse}
+// @Success 207 {object} util.APIResponse{data=dto.PersonBatchResponse}
+// @Failure 400 {object} util.Problem
+// @Failure 406 {object} util.Problem
+// @Failure 413 {object} util.Problem
+// @Failure 422 {object} util.Problem{data=dto.PersonBatchResponse}
+// @Failure 500 {object} util.Problem
+// @Router /api/v1/person/batch/delete [post]
+func (s *Server) DeletePersons() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		atomic, ok := batchMode(w, r)
+		if !ok {
+			return
+		}
+		var req []dto.PersonBatchDeleteRequest
+		if !decodeBatch(w, r, &req) {
+			return
+		}
+
+		persons := make([]domain.Person, len(req))
+		for i, item := range req {
+			persons[i] = domain.Person{Id: item.Id, Version: item.Version}
+		}
+		results, err := s.PersonService.DeletePersons(r.Context(), persons, atomic)
+		s.writeBatchResults(w, results, err, http.StatusOK, "deleted")
+	}
+}
+
+// batchMode reads the mode query parameter, reporting whether the batch is
+// atomic. It writes a 400 response and returns false when mode is unknown.
+func batchMode(w http.ResponseWriter, r *http.Request) (atomic, ok bool) {
+	switch mode := r.URL.Query().Get("mode"); mode {
+	case "", "atomic":
+		return true, true
+	case "best_effort":
+		return false, true
+	default:
+		util.WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("invalid mode %q: want atomic or best_effort", mode))
+		return false, false
+	}
+}
+
+// decodeBatch decodes the JSON array in the request body into v. It writes
+// an error response and returns false when the body cannot be decoded into
+// an array of v's items. The items are validated by the service, one by
+// one, so that each gets its own result.
+func decodeBatch(w http.ResponseWriter, r *http.Request, v any) bool {
+	defer r.Body.Close()
+	if err := util.DecodeJSONLimit(w, r, v, maxBatchBytes); err != nil {
+		util.WriteDomainError(w, err, "Invalid request body")
+		return false
+	}
+	return true
+}
+
+// writeBatchResults reports the outcome of a batch write: 200 when every
+// person succeeded, 207 when a best-effort batch partly failed and 422 when
+// nothing was written because of failed persons. okStatus is the status of
+// a person that succeeded.
+func (s *Server) writeBatchResults(w http.ResponseWriter, results []domain.BatchResult, err error, okStatus int, action string) {
+	if err != nil {
+		s.writeError(w, err, "Failed to write persons")
+		return
+	}
+
+	response := dto.PersonBatchResponse{Results: make([]dto.PersonBatchResult, len(results))}
+	for i, result := range results {
+		item := dto.PersonBatchResult{
+			Index:  i,
+			Id:     result.Person.Id,
+			Status: batchItemStatus(result.Err, okStatus),
+		}
+		if result.Err != nil {
+			item.Error = result.Err.Error()
+			response.Failed++
+		} else {
+			item.Version = result.Person.Version
+			response.Succeeded++
+		}
+		response.Results[i] = item
+	}
+
+	status := http.StatusOK
+	switch {
+	case response.Failed > 0 && response.Succeeded > 0:
+		status = http.StatusMultiStatus
+	case response.Failed > 0:
+		status = http.StatusUnprocessableEntity
+	}
+	message := fmt.Sprintf("%d of %d persons %s", response.Succeeded, len(results), action)
+	if status == http.StatusUnprocessableEntity {
+		util.WriteProblem(w, util.Problem{
+			Status: status,
+			Detail: message,
+			Errors: batchFieldErrors(results),
+			Data:   response,
+		})
+		return
+	}
+	util.WriteResponse(w, status, response.Failed == 0, response, message)
+}
+
+// batchFieldErrors points at the persons of a batch that failed, at their
+// offending fields where known. Persons aborted for the failure of others
+// are left out.
+func batchFieldErrors(results []domain.BatchResult) []util.ProblemField {
+	var fields []util.ProblemField
+	for i, result := range results {
+		if result.Err == nil || errors.Is(result.Err, domain.ErrBatchAborted) {
+			continue
+		}
+		pointer := fmt.Sprintf("/%d", i)
+		if itemFields := util.FieldErrors(result.Err, pointer); len(itemFields) > 0 {
+			fields = append(fields, itemFields...)
+			continue
+		}
+		fields = append(fields, util.ProblemField{Pointer: pointer, Detail: result.Err.Error()})
+	}
+	return fields
+}
+
+// batchItemStatus maps the error of one batch item to the status the item
+// would have had as a single request.
+func batchItemStatus(err error, okStatus int) int {
+	switch {
+	case err == nil:
+		return okStatus
+	case errors.Is(err, domain.ErrBatchAborted):
+		return http.StatusFailedDependency
+	default:
+		return util.ErrorStatus(err)
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/api/dto"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/util"
+)
+
+// @Summary List deleted persons
+// @Description Lists deleted persons that can still be restored, with the same filters, sorting and pagination as the person list.
+// @Tags admin
+// @Produce json
+// @Produce xml
+// @Produce application/msgpack
+// @Produce text/csv
+// @Param limit query int false "Limit number of results, at most 100"
+// @Param offset query int false "Offset for pagination"
+// @Param name query string false "Case-insensitive substring of the name"
+// @Param q query string false "Free-text search; every word must prefix a word of the name or a hobby"
+// @Param sort query string false "Sort field" Enums(created_at, updated_at, name, age)
+// @Param order query string false "Sort direction" Enums(asc, desc)
+// @Param cursor query string false "Opaque cursor from a previous page's links; replaces offset"
+// @Success 200 {object} util.ListResponse{data=[]dto.PersonResponse}
+// @Failure 400 {object} util.Problem
+// @Failure 403 {object} util.Problem
+// @Failure 406 {object} util.Problem
+// @Failure 500 {object} util.Problem
+// @Router /api/v1/admin/persons/deleted [get]
+func (s *Server) GetDeletedPersons() http.HandlerFunc {
+	return s.listPersons(true)
+}
+
+// @Summary Restore a deleted person
+// @Description Brings back a deleted person that has not been purged yet.
+// @Tags person
+// @Produce json
+// @Produce xml
+// @Produce application/msgpack
+// @Param personId path string true "Person ID"
+// @Success 200 {object} dto.PersonResponse
+// @Header 200 {string} ETag "Entity tag of the restored person"
+// @Failure 400 {object} util.Problem
+// @Failure 404 {object} util.Problem
+// @Failure 406 {object} util.Problem
+// @Failure 409 {object} util.Problem
+// @Failure 500 {object} util.Problem
+// @Router /api/v1/person/{personId}/restore [post]
+func (s *Server) RestorePerson() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("personId"))
+		if err != nil {
+			util.WriteErrorResponse(w, http.StatusBadRequest, "Invalid person ID")
+			return
+		}
+
+		person, err := s.PersonService.RestorePerson(r.Context(), id)
+		if err != nil {
+			s.writeError(w, err, "Failed to restore person")
+			return
+		}
+
+		w.Header().Set("ETag", personETag(person.Version))
+		util.WriteSuccessResponse(w, dto.PersonResponse{
+			Id:      person.Id,
+			Name:    person.Name,
+			Age:     person.Age,
+			Hobbies: person.Hobbies,
+		}, fmt.Sprintf("Successfully restored person with ID: %s", person.Id))
+	}
+}
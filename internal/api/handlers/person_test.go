@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNegotiationBeforeWrites(t *testing.T) {
+	const person = `{"name":"Al","age":30,"hobbies":[]}`
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		accept      string
+		wantStatus  int
+		wantPersons int
+	}{
+		{name: "create as csv", method: http.MethodPost, path: "/api/v1/person/create", body: person, accept: "text/csv",
+			wantStatus: http.StatusNotAcceptable},
+		{name: "batch as csv", method: http.MethodPost, path: "/api/v1/person/batch/create", body: "[" + person + "]", accept: "text/csv",
+			wantStatus: http.StatusNotAcceptable},
+		{name: "create as json", method: http.MethodPost, path: "/api/v1/person/create", body: person, accept: "application/json",
+			wantStatus: http.StatusOK, wantPersons: 1},
+		{name: "list as csv", method: http.MethodGet, path: "/api/v1/person", accept: "text/csv",
+			wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t)
+			w := app.do(tt.method, tt.path, tt.body, "Accept", tt.accept)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if got := app.personCount(t); got != tt.wantPersons {
+				t.Errorf("%d persons stored, want %d", got, tt.wantPersons)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/api/dto"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	person_service "github.com/izymalhaw/go-crud/yishakterefe/internal/services/person"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/util"
+)
+
+// maxImportBytes bounds the size of an import.
+const maxImportBytes = 64 << 20
+
+// exportFormats maps the format query parameter of an export to its
+// media type.
+var exportFormats = map[string]person_service.PersonFormat{
+	"csv":    person_service.CSV,
+	"ndjson": person_service.NDJSON,
+}
+
+// @Summary Export persons
+// @Description Streams every person matching the filters as CSV (hobbies joined by ";") or NDJSON. The format is taken from the format parameter, else from the Accept header, and defaults to NDJSON.
+// @Tags person
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "Export format" Enums(csv, ndjson)
+// @Param name query string false "Case-insensitive substring of the name"
+// @Param name_prefix query string false "Case-insensitive prefix of the name"
+// @Param min_age query int false "Minimum age, inclusive"
+// @Param max_age query int false "Maximum age, inclusive"
+// @Param hobby query []string false "Hobby the person must have; repeat to require several" collectionFormat(multi)
+// @Param q query string false "Free-text search; every word must prefix a word of the name or a hobby"
+// @Param sort query string false "Sort field" Enums(created_at, updated_at, name, age)
+// @Param order query string false "Sort direction" Enums(asc, desc)
+// @Success 200 {string} string "Persons"
+// @Failure 400 {object} util.Problem
+// @Failure 500 {object} util.Problem
+// @Router /api/v1/person/export [get]
+func (s *Server) ExportPersons() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format, ok := exportFormat(r)
+		if !ok {
+			util.WriteErrorResponse(w, http.StatusBadRequest, "format must be csv or ndjson")
+			return
+		}
+
+		query, err := personQueryFromRequest(r, person_service.ExportPageSize, 0)
+		if err == nil {
+			err = query.Validate()
+		}
+		if err != nil {
+			util.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		writer, err := person_service.NewPersonWriter(w, format)
+		if err != nil {
+			s.logger.Error("Error exporting persons", "error", err, "request_id", w.Header().Get(util.RequestIDHeader))
+			util.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to export persons")
+			return
+		}
+
+		extension := "ndjson"
+		if format == person_service.CSV {
+			extension = "csv"
+		}
+		w.Header().Set("Content-Type", string(format))
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="persons.%s"`, extension))
+
+		written := 0
+		err = s.PersonService.ExportPersons(r.Context(), query, func(person domain.Person) error {
+			if err := writer.Write(person); err != nil {
+				return err
+			}
+			written++
+			if written%person_service.ExportPageSize == 0 {
+				return writer.Flush()
+			}
+			return nil
+		})
+		if err == nil {
+			err = writer.Flush()
+		}
+		if err != nil {
+			s.logger.Error("Error exporting persons", "error", err, "request_id", w.Header().Get(util.RequestIDHeader))
+			if written == 0 {
+				util.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to export persons")
+				return
+			}
+			// The status is already sent; abort so the client sees the
+			// export is truncated rather than complete.
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
+// exportFormat picks the format of an export from the format parameter or
+// the Accept header. It returns false for an unknown format parameter.
+func exportFormat(r *http.Request) (person_service.PersonFormat, bool) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		format, ok := exportFormats[name]
+		return format, ok
+	}
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		switch format := person_service.PersonFormat(mediaType); format {
+		case person_service.CSV, person_service.NDJSON:
+			return format, true
+		}
+	}
+	return person_service.NDJSON, true
+}
+
+// @Summary Import persons
+// @Description Creates persons from a CSV with a header row (columns id, name, age and hobbies, with hobbies joined by ";"; only name and age are required) or from NDJSON. Every row is validated on its own and rows that fail are reported without stopping the import.
+// @Tags person
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Success 200 {object} util.APIResponse{data=dto.PersonImportReport}
+// @Success 207 {object} util.APIResponse{data=dto.PersonImportReport}
+// @Failure 400 {object} util.Problem{data=dto.PersonImportReport}
+// @Failure 413 {object} util.Problem{data=dto.PersonImportReport}
+// @Failure 415 {object} util.Problem
+// @Failure 422 {object} util.Problem{data=dto.PersonImportReport}
+// @Failure 500 {object} util.Problem
+// @Router /api/v1/person/import [post]
+func (s *Server) ImportPersons() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		format := person_service.PersonFormat(mediaType)
+		if err != nil || (format != person_service.CSV && format != person_service.NDJSON) {
+			util.WriteErrorResponse(w, http.StatusUnsupportedMediaType,
+				fmt.Sprintf("Content-Type must be %s or %s", person_service.CSV, person_service.NDJSON))
+			return
+		}
+
+		body := http.MaxBytesReader(w, r.Body, maxImportBytes)
+		report, err := s.PersonService.ImportPersons(r.Context(), body, format)
+		response := importReportResponse(report)
+		if err != nil {
+			// Batches created before the error stay imported, so the
+			// report goes along with the error.
+			var tooLarge *http.MaxBytesError
+			switch {
+			case errors.As(err, &tooLarge):
+				util.WriteResponse(w, http.StatusRequestEntityTooLarge, false, response, "Request body too large")
+			case util.ErrorStatus(err) != http.StatusInternalServerError:
+				util.WriteResponse(w, util.ErrorStatus(err), false, response, err.Error())
+			case errors.Is(err, bufio.ErrTooLong):
+				util.WriteResponse(w, http.StatusBadRequest, false, response, "NDJSON line too long")
+			default:
+				s.logger.Error("Error importing persons", "error", err, "request_id", w.Header().Get(util.RequestIDHeader))
+				util.WriteResponse(w, http.StatusInternalServerError, false, response, "Failed to import persons")
+			}
+			return
+		}
+
+		status := http.StatusOK
+		switch {
+		case response.Rejected > 0 && response.Imported > 0:
+			status = http.StatusMultiStatus
+		case response.Rejected > 0:
+			status = http.StatusUnprocessableEntity
+		}
+		message := fmt.Sprintf("%d of %d persons imported", response.Imported, response.Imported+response.Rejected)
+		util.WriteResponse(w, status, response.Rejected == 0, response, message)
+	}
+}
+
+func importReportResponse(report person_service.ImportReport) dto.PersonImportReport {
+	response := dto.PersonImportReport{
+		Imported:     report.Imported,
+		Rejected:     len(report.Rejected),
+		RejectedRows: make([]dto.PersonImportRejection, len(report.Rejected)),
+	}
+	for i, rejected := range report.Rejected {
+		response.RejectedRows[i] = dto.PersonImportRejection{Row: rejected.Row, Error: rejected.Err.Error()}
+		if rejected.Id != uuid.Nil {
+			id := rejected.Id
+			response.RejectedRows[i].Id = &id
+		}
+	}
+	return response
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 
 	_ "github.com/izymalhaw/go-crud/yishakterefe/docs"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/util"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
@@ -15,14 +17,65 @@ import (
 func (server *Server) Routes() {
 	// Auth route
 	server.router.HandleFunc("POST /login", server.authHandler.Login())
+	server.router.HandleFunc("POST /auth/login/mfa", server.authHandler.LoginMFA())
+	server.router.HandleFunc("POST /auth/register", server.authHandler.Register())
+	server.router.HandleFunc("POST /auth/refresh", server.authHandler.Refresh())
+	server.router.HandleFunc("POST /auth/logout", server.authHandler.Logout())
+	server.router.HandleFunc("GET /.well-known/jwks.json", server.authHandler.JWKS())
+	server.router.Handle("PUT /auth/password", server.authenticate(server.authHandler.ChangePassword()))
+	server.router.Handle("PUT /auth/users/{userId}/role", server.protect(domain.PermUserManage, server.authHandler.SetRole()))
+	server.router.Handle("POST /api/v1/admin/tokens/revoke", server.protect(domain.PermTokenRevoke, server.authHandler.RevokeTokens()))
+
+	// Two-factor authentication routes
+	server.router.Handle("POST /auth/mfa/totp", server.authenticate(server.authHandler.EnrollTOTP()))
+	server.router.Handle("POST /auth/mfa/totp/confirm", server.authenticate(server.authHandler.ConfirmTOTP()))
+	server.router.Handle("DELETE /auth/mfa/totp", server.authenticate(server.authHandler.DisableTOTP()))
+	server.router.Handle("POST /auth/mfa/recovery-codes", server.authenticate(server.authHandler.RegenerateRecoveryCodes()))
+
+	// API key routes
+	server.router.Handle("POST /auth/api-keys", server.authenticate(server.authHandler.CreateAPIKey()))
+	server.router.Handle("GET /auth/api-keys", server.authenticate(server.authHandler.ListAPIKeys()))
+	server.router.Handle("DELETE /auth/api-keys/{keyId}", server.authenticate(server.authHandler.RevokeAPIKey()))
+	server.router.Handle("POST /auth/api-keys/{keyId}/rotate", server.authenticate(server.authHandler.RotateAPIKey()))
 
 	// Person routes
-	server.router.HandleFunc("POST /api/v1/person/create", server.CreatePerson())
-	server.router.HandleFunc("GET /api/v1/person", server.GetPersons())
-	server.router.HandleFunc("PUT /api/v1/person/{personId}", server.UpdatePerson())
-	server.router.HandleFunc("GET /api/v1/person/{personId}", server.GetPerson())
-	server.router.HandleFunc("DELETE /api/v1/person/{personId}", server.DeletePerson())
+	server.router.Handle("POST /api/v1/person/create", server.protectPersons(domain.PermPersonWrite, server.idempotent(server.CreatePerson())))
+	server.router.Handle("POST /api/v1/person/batch/create", server.protectPersons(domain.PermPersonWrite, server.CreatePersons()))
+	server.router.Handle("POST /api/v1/person/batch/update", server.protectPersons(domain.PermPersonWrite, server.UpdatePersons()))
+	server.router.Handle("POST /api/v1/person/batch/delete", server.protectPersons(domain.PermPersonDelete, server.DeletePersons()))
+	// Exports negotiate their own formats.
+	server.router.Handle("GET /api/v1/person/export", server.protect(domain.PermPersonRead, RequireSecondFactor(server.authService)(server.ExportPersons())))
+	server.router.Handle("POST /api/v1/person/import", server.protectPersons(domain.PermPersonWrite, server.ImportPersons()))
+	server.router.Handle("GET /api/v1/person", server.protectPersonList(domain.PermPersonRead, server.GetPersons()))
+	server.router.Handle("PUT /api/v1/person/{personId}", server.protectPersons(domain.PermPersonWrite, server.UpdatePerson()))
+	server.router.Handle("PATCH /api/v1/person/{personId}", server.protectPersons(domain.PermPersonWrite, server.PatchPerson()))
+	server.router.Handle("GET /api/v1/person/{personId}", server.protectPersons(domain.PermPersonRead, server.GetPerson()))
+	server.router.Handle("DELETE /api/v1/person/{personId}", server.protectPersons(domain.PermPersonDelete, server.DeletePerson()))
+	server.router.Handle("POST /api/v1/person/{personId}/restore", server.protectPersons(domain.PermPersonRestore, server.RestorePerson()))
+	server.router.Handle("GET /api/v1/admin/persons/deleted", server.protectPersonList(domain.PermPersonRestore, server.GetDeletedPersons()))
 
 	server.router.HandleFunc("/", http.HandlerFunc(server.HandleNotFound))
 	server.router.HandleFunc("/swagger/", httpSwagger.WrapHandler)
+}
+
+// authenticate requires an authenticated caller before h runs.
+func (server *Server) authenticate(h http.Handler) http.Handler {
+	return AuthMiddleware(server.authService, server.logger)(h)
+}
+
+// protect requires an authenticated caller holding perm before h runs.
+func (server *Server) protect(perm domain.Permission, h http.Handler) http.Handler {
+	return server.authenticate(RequirePermission(perm)(h))
+}
+
+// protectPersons is protect plus the second-factor requirement for admins
+// that guards person data. Responses are negotiated from the Accept header.
+func (server *Server) protectPersons(perm domain.Permission, h http.Handler) http.Handler {
+	return server.protect(perm, RequireSecondFactor(server.authService)(util.Negotiate(h)))
+}
+
+// protectPersonList is protectPersons for the person lists, which may also
+// be sent as CSV.
+func (server *Server) protectPersonList(perm domain.Permission, h http.Handler) http.Handler {
+	return server.protect(perm, RequireSecondFactor(server.authService)(util.NegotiateList(h)))
 }
\ No newline at end of file
@@ -16,17 +16,37 @@ var (
 	ErrInvalidLevel = errors.New("invalid log level")
 	ErrInvalidEnv   = errors.New("env not set or invalid")
 	ErrDatabaseURL  = errors.New("database URL not set")
+	ErrJWTKeysDir   = errors.New("JWT_KEYS_DIR not set")
+	ErrCursorSecret = errors.New("CURSOR_SECRET not set")
 )
 
 type Config struct {
-	Port           int
-	LogLevel       slog.Level
-	Env            string
-	DatabaseURL    string
-	DBMaxOpenConns int
-	DBMaxIdleConns int
-	DBMaxLifetime  time.Duration
-	JWTSecret      string
+	Port               int
+	LogLevel           slog.Level
+	Env                string
+	DatabaseURL        string
+	DBMaxOpenConns     int
+	DBMaxIdleConns     int
+	DBMaxLifetime      time.Duration
+	JWTKeysDir         string
+	JWTActiveKID       string
+	AccessTokenTTL     time.Duration
+	RefreshTokenTTL    time.Duration
+	PruneInterval      time.Duration
+	LoginMaxAttempts   int
+	LoginMaxIPAttempts int
+	LoginBackoffBase   time.Duration
+	LoginLockout       time.Duration
+	TrustProxyHeaders  bool
+	MFAIssuer          string
+	AdminMFARequired   bool
+	CursorSecret       string
+	PersonRetention    time.Duration
+	PersonPurgeEvery   time.Duration
+	IdempotencyTTL     time.Duration
+	MigrateOnStart     bool
+	AdminEmail         string
+	AdminPassword      string
 }
 
 var Environment = map[string]string{
@@ -104,11 +124,124 @@ func (c *Config) loadEnv() error {
 		}
 	}
 
-	// ✅ Fix JWT_SECRET logic before return
-	c.JWTSecret = os.Getenv("JWT_SECRET")
-	if c.JWTSecret == "" {
-		return errors.New("JWT_SECRET not set")
+	// Apply pending schema migrations at startup when enabled
+	if migrate := os.Getenv("MIGRATE_ON_START"); migrate != "" {
+		if val, err := strconv.ParseBool(migrate); err == nil {
+			c.MigrateOnStart = val
+		}
+	}
+
+	// Token signing keys; development falls back to an ephemeral key
+	c.JWTKeysDir = os.Getenv("JWT_KEYS_DIR")
+	if c.JWTKeysDir == "" && c.Env == Environment["prod"] {
+		return ErrJWTKeysDir
+	}
+	c.JWTActiveKID = os.Getenv("JWT_ACTIVE_KID")
+
+	// Token lifetimes with defaults
+	c.AccessTokenTTL = 15 * time.Minute
+	if ttl := os.Getenv("ACCESS_TOKEN_TTL"); ttl != "" {
+		if val, err := time.ParseDuration(ttl); err == nil && val > 0 {
+			c.AccessTokenTTL = val
+		}
+	}
+
+	c.RefreshTokenTTL = 30 * 24 * time.Hour
+	if ttl := os.Getenv("REFRESH_TOKEN_TTL"); ttl != "" {
+		if val, err := time.ParseDuration(ttl); err == nil && val > 0 {
+			c.RefreshTokenTTL = val
+		}
+	}
+
+	// How often expired token revocations are pruned
+	c.PruneInterval = time.Hour
+	if interval := os.Getenv("REVOCATION_PRUNE_INTERVAL"); interval != "" {
+		if val, err := time.ParseDuration(interval); err == nil && val > 0 {
+			c.PruneInterval = val
+		}
+	}
+
+	// Login brute-force protection with defaults
+	c.LoginMaxAttempts = 5
+	if attempts := os.Getenv("LOGIN_MAX_ATTEMPTS"); attempts != "" {
+		if val, err := strconv.Atoi(attempts); err == nil && val >= 0 {
+			c.LoginMaxAttempts = val
+		}
+	}
+
+	c.LoginMaxIPAttempts = 20
+	if attempts := os.Getenv("LOGIN_MAX_IP_ATTEMPTS"); attempts != "" {
+		if val, err := strconv.Atoi(attempts); err == nil && val >= 0 {
+			c.LoginMaxIPAttempts = val
+		}
+	}
+
+	c.LoginBackoffBase = time.Second
+	if base := os.Getenv("LOGIN_BACKOFF_BASE"); base != "" {
+		if val, err := time.ParseDuration(base); err == nil && val >= 0 {
+			c.LoginBackoffBase = val
+		}
+	}
+
+	c.LoginLockout = 15 * time.Minute
+	if lockout := os.Getenv("LOGIN_LOCKOUT_DURATION"); lockout != "" {
+		if val, err := time.ParseDuration(lockout); err == nil && val > 0 {
+			c.LoginLockout = val
+		}
+	}
+
+	// Take the client IP from X-Forwarded-For; only enable behind a proxy
+	if trust := os.Getenv("TRUST_PROXY_HEADERS"); trust != "" {
+		if val, err := strconv.ParseBool(trust); err == nil {
+			c.TrustProxyHeaders = val
+		}
+	}
+
+	// Two-factor authentication
+	c.MFAIssuer = "go-crud"
+	if issuer := os.Getenv("MFA_ISSUER"); issuer != "" {
+		c.MFAIssuer = issuer
+	}
+
+	c.AdminMFARequired = true
+	if required := os.Getenv("ADMIN_MFA_REQUIRED"); required != "" {
+		if val, err := strconv.ParseBool(required); err == nil {
+			c.AdminMFARequired = val
+		}
+	}
+
+	// Key signing pagination cursors; development falls back to a random one
+	c.CursorSecret = os.Getenv("CURSOR_SECRET")
+	if c.CursorSecret == "" && c.Env == Environment["prod"] {
+		return ErrCursorSecret
+	}
+
+	// How long deleted persons can be restored before they are purged
+	c.PersonRetention = 30 * 24 * time.Hour
+	if retention := os.Getenv("PERSON_RETENTION"); retention != "" {
+		if val, err := time.ParseDuration(retention); err == nil && val >= 0 {
+			c.PersonRetention = val
+		}
 	}
 
+	c.PersonPurgeEvery = time.Hour
+	if interval := os.Getenv("PERSON_PURGE_INTERVAL"); interval != "" {
+		if val, err := time.ParseDuration(interval); err == nil && val > 0 {
+			c.PersonPurgeEvery = val
+		}
+	}
+
+	// How long responses to requests with an Idempotency-Key are replayed
+	c.IdempotencyTTL = 24 * time.Hour
+	if ttl := os.Getenv("IDEMPOTENCY_TTL"); ttl != "" {
+		if val, err := time.ParseDuration(ttl); err == nil && val > 0 {
+			c.IdempotencyTTL = val
+		}
+	}
+
+	// Optional bootstrap administrator account
+	c.AdminEmail = os.Getenv("ADMIN_EMAIL")
+	c.AdminPassword = os.Getenv("ADMIN_PASSWORD")
+
 	return nil
 }
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrAPIKeyNotFound = NewError(ErrNotFound, "api key not found")
+
+// APIKey is a long-lived credential for machine-to-machine clients. Only a
+// hash of the secret is stored; Prefix identifies the key in logs and
+// listings without revealing it.
+type APIKey struct {
+	Id         uuid.UUID
+	UserID     uuid.UUID
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scopes     []Permission
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// Active reports whether the key may still be used at now.
+func (k APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
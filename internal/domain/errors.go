@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// Error kinds classify failures by what went wrong, independently of the
+// transport. Specific errors such as ErrPersonNotFound are of one kind, so
+// callers can test for either: errors.Is(err, ErrPersonNotFound) or
+// errors.Is(err, ErrNotFound). Errors of no kind are internal failures.
+var (
+	// ErrInvalidInput means a request is malformed, e.g. an unknown sort
+	// field.
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrValidation means well-formed data breaks a rule, e.g. a negative
+	// age.
+	ErrValidation = errors.New("validation failed")
+	// ErrUnauthorized means the caller's credentials were not accepted.
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrNotFound     = errors.New("not found")
+	// ErrConflict means the request clashes with the current state, e.g.
+	// an id that is already taken.
+	ErrConflict = errors.New("conflict")
+	// ErrPreconditionFailed means a conditional write found a different
+	// version than it expected.
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrTooLarge means a request holds more than is accepted at once,
+	// e.g. a batch over the size limit.
+	ErrTooLarge = errors.New("too large")
+)
+
+// kindError is a specific error of a kind.
+type kindError struct {
+	kind    error
+	message string
+}
+
+func (e *kindError) Error() string { return e.message }
+
+func (e *kindError) Unwrap() error { return e.kind }
+
+// NewError returns a specific error of kind with message.
+func NewError(kind error, message string) error {
+	return &kindError{kind: kind, message: message}
+}
+
+// FieldError is an error about one field of the input. Field is its path
+// as named in request bodies, with names and list indexes separated by
+// slashes, e.g. "hobbies/2". It is of the kind of Err and reads as Err.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string { return e.Err.Error() }
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// FieldErrors lists every field error found in one input.
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e FieldErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Err returns e as an error, nil when it is empty.
+func (e FieldErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+var (
+	ErrIdempotencyKeyInUse    = NewError(ErrConflict, "a request with this idempotency key is still in progress")
+	ErrIdempotencyKeyMismatch = NewError(ErrValidation, "idempotency key was already used for a different request")
+)
+
+// IdempotencyRecord is a request sent with an Idempotency-Key and, once it
+// has completed, its response. Keys are scoped to the principal that sent
+// them; RequestHash tells a retry from a different request under the same
+// key. A record without StatusCode is of a request still in progress.
+type IdempotencyRecord struct {
+	Principal   string
+	Key         string
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	// ExpiresAt ends the record: a reservation that outlives it was
+	// abandoned, a completed record is no longer replayed.
+	ExpiresAt time.Time
+}
+
+// Completed reports whether the record holds a response.
+func (r IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
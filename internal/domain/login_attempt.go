@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// LoginThrottle is the failed-login state tracked for one account or client
+// IP.
+type LoginThrottle struct {
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
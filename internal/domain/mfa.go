@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTOTPNotFound        = NewError(ErrNotFound, "totp not enrolled")
+	ErrTOTPStepUsed        = NewError(ErrUnauthorized, "totp code already used")
+	ErrRecoveryCodeInvalid = NewError(ErrUnauthorized, "recovery code invalid or already used")
+)
+
+// TOTPEnrollment is a user's TOTP secret. It only counts as a second factor
+// once ConfirmedAt is set, which happens after the user proves their
+// authenticator produces valid codes. LastUsedStep is the time step of the
+// last accepted code, so that a code cannot be replayed.
+type TOTPEnrollment struct {
+	UserID       uuid.UUID
+	Secret       string
+	ConfirmedAt  *time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+}
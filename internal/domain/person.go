@@ -1,12 +1,172 @@
 package domain
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
 	"github.com/google/uuid"
 )
 
+// Limits on the fields of a person. The validate tags of
+// dto.PersonRequest state the same limits.
+const (
+	MaxPersonNameLength = 100
+	MaxPersonAge        = 150
+	MaxHobbies          = 20
+	MaxHobbyLength      = 50
+)
+
+var (
+	ErrPersonNotFound     = NewError(ErrNotFound, "person not found")
+	ErrPersonNameRequired = NewError(ErrValidation, "name is required")
+	ErrPersonNameTooLong  = NewError(ErrValidation, fmt.Sprintf("name must be at most %d characters", MaxPersonNameLength))
+	ErrPersonNameInvalid  = NewError(ErrValidation, "name may only contain letters, spaces, apostrophes, hyphens and periods")
+	ErrPersonAgeNegative  = NewError(ErrValidation, "age must not be negative")
+	ErrPersonAgeTooHigh   = NewError(ErrValidation, fmt.Sprintf("age must be at most %d", MaxPersonAge))
+	ErrTooManyHobbies     = NewError(ErrValidation, fmt.Sprintf("a person may have at most %d hobbies", MaxHobbies))
+	ErrHobbyInvalid       = NewError(ErrValidation, fmt.Sprintf("a hobby must be 1 to %d letters, digits, spaces or - ' & + . characters", MaxHobbyLength))
+	ErrDuplicateHobby     = NewError(ErrValidation, "hobbies must not repeat, ignoring case")
+	// ErrPersonVersionMismatch means the person changed since the version a
+	// conditional write was based on.
+	ErrPersonVersionMismatch = NewError(ErrPreconditionFailed, "person has been modified")
+	ErrPersonNotDeleted      = NewError(ErrConflict, "person is not deleted")
+	// ErrPersonDeleted means the id belongs to a deleted person, which has
+	// to be restored before it can be written again.
+	ErrPersonDeleted = NewError(ErrConflict, "person is deleted")
+)
+
+// Person is a stored person. Version starts at 1 and grows with every
+// write; passed to a write it is the version the write expects to replace,
+// or 0 for an unconditional write. Deleted persons keep their row with
+// DeletedAt set until they are purged.
 type Person struct {
-	Id      uuid.UUID
-	Name    string
-	Age     int
-	Hobbies []string
+	Id        uuid.UUID
+	Name      string
+	Age       int
+	Hobbies   []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   int64
+	DeletedAt *time.Time
+}
+
+// PersonField names a field of Person that clients can change.
+type PersonField string
+
+const (
+	PersonFieldName    PersonField = "name"
+	PersonFieldAge     PersonField = "age"
+	PersonFieldHobbies PersonField = "hobbies"
+)
+
+// Validate checks the invariants every stored person satisfies and reports
+// every field that breaks one.
+func (p Person) Validate() error {
+	var errs FieldErrors
+	add := func(field string, err error) {
+		errs = append(errs, &FieldError{Field: field, Err: err})
+	}
+
+	switch {
+	case strings.TrimSpace(p.Name) == "":
+		add(string(PersonFieldName), ErrPersonNameRequired)
+	case utf8.RuneCountInString(p.Name) > MaxPersonNameLength:
+		add(string(PersonFieldName), ErrPersonNameTooLong)
+	case !ValidPersonName(p.Name):
+		add(string(PersonFieldName), ErrPersonNameInvalid)
+	}
+
+	switch {
+	case p.Age < 0:
+		add(string(PersonFieldAge), ErrPersonAgeNegative)
+	case p.Age > MaxPersonAge:
+		add(string(PersonFieldAge), ErrPersonAgeTooHigh)
+	}
+
+	if len(p.Hobbies) > MaxHobbies {
+		add(string(PersonFieldHobbies), ErrTooManyHobbies)
+	} else if HasDuplicateHobbies(p.Hobbies) {
+		add(string(PersonFieldHobbies), ErrDuplicateHobby)
+	}
+	for i, hobby := range p.Hobbies {
+		if !ValidHobby(hobby) {
+			add(fmt.Sprintf("%s/%d", PersonFieldHobbies, i), ErrHobbyInvalid)
+		}
+	}
+	return errs.Err()
+}
+
+// ValidPersonName reports whether name is made of letters, combining marks,
+// single inner spaces, apostrophes, hyphens and periods, and starts with a
+// letter. It does not check the length.
+func ValidPersonName(name string) bool {
+	previous := ' '
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r), unicode.Is(unicode.Mn, r):
+		case i == 0:
+			return false
+		case r == ' ':
+			if previous == ' ' {
+				return false
+			}
+		case r == '\'', r == '’', r == '-', r == '.':
+		default:
+			return false
+		}
+		previous = r
+	}
+	return previous != ' '
+}
+
+// ValidHobby reports whether hobby has 1 to MaxHobbyLength characters, all
+// letters, digits, spaces or - ' & + ., and is not blank. The ";" that
+// joins hobbies in CSV is thereby excluded.
+func ValidHobby(hobby string) bool {
+	if strings.TrimSpace(hobby) == "" || utf8.RuneCountInString(hobby) > MaxHobbyLength {
+		return false
+	}
+	for _, r := range hobby {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), unicode.Is(unicode.Mn, r):
+		case strings.ContainsRune(" -'&+.", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// HasDuplicateHobbies reports whether two hobbies are equal ignoring case
+// and surrounding spaces.
+func HasDuplicateHobbies(hobbies []string) bool {
+	seen := make(map[string]bool, len(hobbies))
+	for _, hobby := range hobbies {
+		key := strings.ToLower(strings.TrimSpace(hobby))
+		if seen[key] {
+			return true
+		}
+		seen[key] = true
+	}
+	return false
+}
+
+// ChangedFields returns the client-editable fields whose values differ
+// between p and other.
+func (p Person) ChangedFields(other Person) []PersonField {
+	var fields []PersonField
+	if p.Name != other.Name {
+		fields = append(fields, PersonFieldName)
+	}
+	if p.Age != other.Age {
+		fields = append(fields, PersonFieldAge)
+	}
+	if !slices.Equal(p.Hobbies, other.Hobbies) {
+		fields = append(fields, PersonFieldHobbies)
+	}
+	return fields
 }
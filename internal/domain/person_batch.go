@@ -0,0 +1,17 @@
+package domain
+
+import "errors"
+
+var (
+	ErrPersonExists = NewError(ErrConflict, "person already exists")
+	// ErrBatchAborted marks items of an atomic batch that were valid but
+	// not written because another item failed.
+	ErrBatchAborted = errors.New("not applied because another item in the batch failed")
+)
+
+// BatchResult is the outcome of one item of a batch write: the person as
+// stored, or Err explaining why the item was not written.
+type BatchResult struct {
+	Person Person
+	Err    error
+}
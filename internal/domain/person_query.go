@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidSortField = NewError(ErrInvalidInput, "invalid sort field")
+	ErrInvalidAgeRange  = NewError(ErrInvalidInput, "min_age must not be greater than max_age")
+	ErrInvalidCursor    = NewError(ErrInvalidInput, "invalid cursor")
+	ErrCursorMismatch   = NewError(ErrInvalidInput, "cursor was issued for a different sort order")
+)
+
+// PersonSortField is a field the person list can be ordered by.
+type PersonSortField string
+
+const (
+	SortByCreatedAt PersonSortField = "created_at"
+	SortByUpdatedAt PersonSortField = "updated_at"
+	SortByName      PersonSortField = "name"
+	SortByAge       PersonSortField = "age"
+)
+
+// Valid reports whether f is a known sort field.
+func (f PersonSortField) Valid() bool {
+	switch f {
+	case SortByCreatedAt, SortByUpdatedAt, SortByName, SortByAge:
+		return true
+	}
+	return false
+}
+
+// PersonFilter narrows the person list. Every set criterion must match.
+//
+// Name matching is case-insensitive. Hobbies must all be present, compared
+// exactly. Search is split into terms by SearchTerms, and every term must
+// prefix a word of the name or of a hobby. Deleted selects deleted persons
+// instead of live ones.
+type PersonFilter struct {
+	NameContains string
+	NamePrefix   string
+	MinAge       *int
+	MaxAge       *int
+	Hobbies      []string
+	Search       string
+	Deleted      bool
+}
+
+// PersonQuery selects a page of persons. Results are ordered by SortBy and
+// then by id, both in the same direction, so the order is total. Names sort
+// case-insensitively by their lowercase form, byte by byte.
+//
+// With a Cursor the page starts right after it (or ends right before it)
+// and Offset is ignored.
+type PersonQuery struct {
+	PersonFilter
+	SortBy   PersonSortField
+	SortDesc bool
+	Limit    int
+	Offset   int
+	Cursor   *PersonCursor
+}
+
+// PersonCursor is a position in the person list: the sort key of the person
+// a page ended or started at. Only the field named by SortBy is set besides
+// Id. Before selects the page preceding the position rather than the one
+// following it.
+type PersonCursor struct {
+	SortBy    PersonSortField
+	SortDesc  bool
+	Before    bool
+	Id        uuid.UUID
+	Name      string
+	Age       int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// CursorAt returns the cursor positioned at person in the order of q.
+func (q PersonQuery) CursorAt(person Person, before bool) PersonCursor {
+	cursor := PersonCursor{
+		SortBy:   q.SortBy,
+		SortDesc: q.SortDesc,
+		Before:   before,
+		Id:       person.Id,
+	}
+	switch q.SortBy {
+	case SortByName:
+		cursor.Name = person.Name
+	case SortByAge:
+		cursor.Age = person.Age
+	case SortByUpdatedAt:
+		cursor.UpdatedAt = person.UpdatedAt
+	default:
+		cursor.CreatedAt = person.CreatedAt
+	}
+	return cursor
+}
+
+// Key returns the person whose sort key the cursor holds, for comparing
+// against other persons.
+func (c PersonCursor) Key() Person {
+	return Person{
+		Id:        c.Id,
+		Name:      c.Name,
+		Age:       c.Age,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+// DefaultPersonQuery lists the newest persons first.
+func DefaultPersonQuery(limit, offset int) PersonQuery {
+	return PersonQuery{
+		SortBy:   SortByCreatedAt,
+		SortDesc: true,
+		Limit:    limit,
+		Offset:   offset,
+	}
+}
+
+// Validate checks the query for contradictory or unknown values.
+func (q PersonQuery) Validate() error {
+	if !q.SortBy.Valid() {
+		return ErrInvalidSortField
+	}
+	if q.MinAge != nil && q.MaxAge != nil && *q.MinAge > *q.MaxAge {
+		return ErrInvalidAgeRange
+	}
+	if q.Cursor != nil && (q.Cursor.SortBy != q.SortBy || q.Cursor.SortDesc != q.SortDesc) {
+		return ErrCursorMismatch
+	}
+	return nil
+}
+
+// SearchTerms splits free text into lowercase words made of letters and
+// digits, the same way names and hobbies are indexed for search.
+func SearchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// PersonSearchTerms returns the words person is found by in a search: the
+// SearchTerms of its name and of each hobby. Repositories index exactly
+// these words rather than tokenizing the text themselves.
+func PersonSearchTerms(person Person) []string {
+	words := SearchTerms(person.Name)
+	for _, hobby := range person.Hobbies {
+		words = append(words, SearchTerms(hobby)...)
+	}
+	return words
+}
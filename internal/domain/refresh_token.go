@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRefreshTokenNotFound = NewError(ErrNotFound, "refresh token not found")
+	ErrRefreshTokenRevoked  = NewError(ErrConflict, "refresh token already revoked")
+)
+
+// RefreshToken is a server-side record of an opaque refresh token. Tokens
+// rotated from the same login share a FamilyID so that a replayed token can
+// revoke the whole session.
+type RefreshToken struct {
+	Id         uuid.UUID
+	UserID     uuid.UUID
+	FamilyID   uuid.UUID
+	TokenHash  string
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	RevokedAt  *time.Time
+	ReplacedBy *uuid.UUID
+	// MFA records that the session was started with a second factor.
+	MFA bool
+}
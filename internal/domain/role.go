@@ -0,0 +1,42 @@
+package domain
+
+// Role groups a set of permissions granted to a user.
+type Role string
+
+// Permission is a single action a principal may perform.
+type Permission string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+)
+
+const (
+	PermPersonRead    Permission = "person:read"
+	PermPersonWrite   Permission = "person:write"
+	PermPersonDelete  Permission = "person:delete"
+	PermPersonRestore Permission = "person:restore"
+	PermUserManage    Permission = "user:manage"
+	PermTokenRevoke   Permission = "token:revoke"
+)
+
+// DefaultRole is assigned to self-registered accounts.
+const DefaultRole = RoleViewer
+
+var RolePermissions = map[Role][]Permission{
+	RoleAdmin:  {PermPersonRead, PermPersonWrite, PermPersonDelete, PermPersonRestore, PermUserManage, PermTokenRevoke},
+	RoleEditor: {PermPersonRead, PermPersonWrite, PermPersonDelete},
+	RoleViewer: {PermPersonRead},
+}
+
+// Valid reports whether r is a known role.
+func (r Role) Valid() bool {
+	_, ok := RolePermissions[r]
+	return ok
+}
+
+// Permissions returns the permissions granted to r.
+func (r Role) Permissions() []Permission {
+	return RolePermissions[r]
+}
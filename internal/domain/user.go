@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUserNotFound = NewError(ErrNotFound, "user not found")
+	ErrEmailTaken   = NewError(ErrConflict, "email already registered")
+)
+
+type User struct {
+	Id           uuid.UUID
+	Email        string
+	PasswordHash string
+	Role         Role
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
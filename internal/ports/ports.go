@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
@@ -9,8 +10,132 @@ import (
 
 type PersonRepository interface {
 	CreatePerson(ctx context.Context, person domain.Person) error
-	GetAllPersons(ctx context.Context, limit, offset int) ([]domain.Person, error)
-	UpdatePerson(ctx context.Context, person domain.Person) error
-	DeletePerson(ctx context.Context, id uuid.UUID) error
+	GetAllPersons(ctx context.Context, query domain.PersonQuery) ([]domain.Person, error)
+	// CountPersons returns how many persons match filter in total.
+	CountPersons(ctx context.Context, filter domain.PersonFilter) (int, error)
+	// UpdatePerson overwrites person and bumps its version. A non-zero
+	// person.Version must match the stored one, otherwise it returns
+	// domain.ErrPersonVersionMismatch. It returns the stored person.
+	UpdatePerson(ctx context.Context, person domain.Person) (*domain.Person, error)
+	// UpsertPerson inserts person at version 1 if its id is free and
+	// otherwise overwrites it as UpdatePerson does, keeping CreatedAt. It
+	// reports whether the person was created. A non-zero person.Version
+	// only matches an existing person, and the id of a deleted person gets
+	// domain.ErrPersonDeleted.
+	UpsertPerson(ctx context.Context, person domain.Person) (*domain.Person, bool, error)
+	// PatchPerson is UpdatePerson restricted to fields, along with UpdatedAt,
+	// leaving the other columns as they are.
+	PatchPerson(ctx context.Context, person domain.Person, fields []domain.PersonField) (*domain.Person, error)
+	// DeletePerson marks a live person deleted at the given time and bumps
+	// its version; a non-zero version must match the stored one. Deleted
+	// persons are invisible to every other read and write.
+	DeletePerson(ctx context.Context, id uuid.UUID, version int64, at time.Time) error
+	// RestorePerson brings back a deleted person, returning
+	// domain.ErrPersonNotDeleted if it is live.
+	RestorePerson(ctx context.Context, id uuid.UUID, at time.Time) (*domain.Person, error)
+	// PurgePersons hard-deletes persons deleted before the given time.
+	PurgePersons(ctx context.Context, before time.Time) (int64, error)
+
+	// The batch methods write many persons in one transaction and return a
+	// result per person, in order. With atomic set nothing is written
+	// unless every person is, and the persons that could have been written
+	// get domain.ErrBatchAborted. Ids must be unique within a batch.
+
+	// CreatePersons inserts persons; existing ids get domain.ErrPersonExists.
+	CreatePersons(ctx context.Context, persons []domain.Person, atomic bool) ([]domain.BatchResult, error)
+	// UpdatePersons overwrites persons with the version semantics of
+	// UpdatePerson.
+	UpdatePersons(ctx context.Context, persons []domain.Person, atomic bool) ([]domain.BatchResult, error)
+	// DeletePersons deletes the persons identified by Id, each at Version
+	// unless it is 0, as DeletePerson does.
+	DeletePersons(ctx context.Context, persons []domain.Person, at time.Time, atomic bool) ([]domain.BatchResult, error)
 	GetPerson(ctx context.Context, userID uuid.UUID) (*domain.Person, error)
 }
+
+type UserRepository interface {
+	CreateUser(ctx context.Context, user domain.User) error
+	GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error
+	UpdateRole(ctx context.Context, id uuid.UUID, role domain.Role) error
+}
+
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token domain.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	// RotateRefreshToken revokes oldID, marks it as replaced by next and stores
+	// next atomically. It returns domain.ErrRefreshTokenRevoked if oldID was
+	// already revoked.
+	RotateRefreshToken(ctx context.Context, oldID uuid.UUID, next domain.RefreshToken) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error
+}
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key domain.APIKey) error
+	GetAPIKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error)
+	ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+	// RotateAPIKey revokes oldID and stores next atomically.
+	RotateAPIKey(ctx context.Context, oldID uuid.UUID, next domain.APIKey) error
+	TouchAPIKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+}
+
+// RevocationRepository tracks access tokens revoked before their expiry.
+// Entries are only needed until expiresAt, after which the token would be
+// rejected anyway and the entry can be pruned.
+type RevocationRepository interface {
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	// RevokeUserTokens revokes every token of userID issued before before.
+	RevokeUserTokens(ctx context.Context, userID uuid.UUID, before, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, jti string, userID uuid.UUID, issuedAt time.Time) (bool, error)
+	PruneRevocations(ctx context.Context, now time.Time) (int64, error)
+}
+
+// LoginAttemptRepository tracks failed logins per key, where a key names an
+// account or a client IP.
+type LoginAttemptRepository interface {
+	GetLoginThrottle(ctx context.Context, key string) (domain.LoginThrottle, error)
+	// RecordLoginFailure counts a failure at now. Failures older than window
+	// are forgotten before counting.
+	RecordLoginFailure(ctx context.Context, key string, now time.Time, window time.Duration) (domain.LoginThrottle, error)
+	LockLogin(ctx context.Context, key string, until time.Time) error
+	ResetLoginFailures(ctx context.Context, key string) error
+	// PruneLoginAttempts drops unlocked entries whose last failure is before
+	// before.
+	PruneLoginAttempts(ctx context.Context, before time.Time) (int64, error)
+}
+
+// MFARepository stores TOTP enrollments and the hashes of recovery codes.
+type MFARepository interface {
+	// SaveTOTP stores a pending enrollment, replacing any unconfirmed one.
+	SaveTOTP(ctx context.Context, enrollment domain.TOTPEnrollment) error
+	GetTOTP(ctx context.Context, userID uuid.UUID) (*domain.TOTPEnrollment, error)
+	// ConfirmTOTP activates the enrollment at step and replaces the user's
+	// recovery codes with codeHashes.
+	ConfirmTOTP(ctx context.Context, userID uuid.UUID, step int64, codeHashes []string) error
+	// UseTOTPStep records step as used. It fails with ErrTOTPStepUsed unless
+	// step is later than the last used one.
+	UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error
+	DeleteTOTP(ctx context.Context, userID uuid.UUID) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	// UseRecoveryCode marks an unused code as used, failing with
+	// ErrRecoveryCodeInvalid otherwise.
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error
+}
+
+// IdempotencyRepository stores requests sent with an Idempotency-Key and
+// their responses, keyed by principal and key.
+type IdempotencyRepository interface {
+	// ReserveIdempotencyKey stores record unless an unexpired record holds
+	// its key, in which case it returns that record and false.
+	ReserveIdempotencyKey(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error)
+	// CompleteIdempotencyKey stores the response of a reserved record and
+	// its new expiry.
+	CompleteIdempotencyKey(ctx context.Context, record domain.IdempotencyRecord) error
+	// ReleaseIdempotencyKey drops a reservation, so the key can be retried.
+	ReleaseIdempotencyKey(ctx context.Context, principal, key string) error
+	// PruneIdempotencyKeys drops records that expired before now.
+	PruneIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+}
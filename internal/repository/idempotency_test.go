@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/ports"
+)
+
+var idempotencyRepositories = []adapter[ports.IdempotencyRepository]{
+	{name: "memory", new: func(t *testing.T) ports.IdempotencyRepository { return NewInMemoryIdempotencyStore() }},
+	{name: "postgres", new: func(t *testing.T) ports.IdempotencyRepository {
+		db := testDB(t, "idempotency_keys")
+		if db == nil {
+			t.Skip("TEST_DATABASE_URL is not set")
+		}
+		return NewPostgresIdempotencyStore(db)
+	}},
+}
+
+// reservation is the record of a request with hash reserving key at
+// testTime for a minute.
+func reservation(principal, key, hash string) domain.IdempotencyRecord {
+	return domain.IdempotencyRecord{
+		Principal:   principal,
+		Key:         key,
+		RequestHash: hash,
+		CreatedAt:   testTime,
+		ExpiresAt:   testTime.Add(time.Minute),
+	}
+}
+
+func TestIdempotencyRepository(t *testing.T) {
+	withAdapters(t, idempotencyRepositories, func(t *testing.T, newStore func(t *testing.T) ports.IdempotencyRepository) {
+		reserve := func(t *testing.T, store ports.IdempotencyRepository, record domain.IdempotencyRecord, wantReserved bool) domain.IdempotencyRecord {
+			t.Helper()
+			got, reserved, err := store.ReserveIdempotencyKey(context.Background(), record)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reserved != wantReserved {
+				t.Fatalf("ReserveIdempotencyKey(%s) reserved = %v, want %v", record.Key, reserved, wantReserved)
+			}
+			return got
+		}
+		complete := func(t *testing.T, store ports.IdempotencyRepository, principal, key string) {
+			t.Helper()
+			err := store.CompleteIdempotencyKey(context.Background(), domain.IdempotencyRecord{
+				Principal:   principal,
+				Key:         key,
+				StatusCode:  201,
+				ContentType: "application/json",
+				Body:        []byte(`{"id":1}`),
+				ExpiresAt:   testTime.Add(24 * time.Hour),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		t.Run("reserve and replay", func(t *testing.T) {
+			store := newStore(t)
+			reserve(t, store, reservation("alice", "k", "hash"), true)
+
+			inProgress := reserve(t, store, reservation("alice", "k", "hash"), false)
+			if inProgress.Completed() || inProgress.RequestHash != "hash" {
+				t.Errorf("record in progress = %+v", inProgress)
+			}
+
+			complete(t, store, "alice", "k")
+			done := reserve(t, store, reservation("alice", "k", "other"), false)
+			if !done.Completed() || done.StatusCode != 201 || done.ContentType != "application/json" ||
+				!bytes.Equal(done.Body, []byte(`{"id":1}`)) || done.RequestHash != "hash" {
+				t.Errorf("completed record = %+v", done)
+			}
+		})
+
+		t.Run("keys are per principal", func(t *testing.T) {
+			store := newStore(t)
+			reserve(t, store, reservation("alice", "k", "hash"), true)
+			reserve(t, store, reservation("bob", "k", "hash"), true)
+		})
+
+		t.Run("release frees a reservation", func(t *testing.T) {
+			store := newStore(t)
+			reserve(t, store, reservation("alice", "k", "hash"), true)
+			if err := store.ReleaseIdempotencyKey(context.Background(), "alice", "k"); err != nil {
+				t.Fatal(err)
+			}
+			reserve(t, store, reservation("alice", "k", "other"), true)
+		})
+
+		t.Run("release keeps a completed key", func(t *testing.T) {
+			store := newStore(t)
+			reserve(t, store, reservation("alice", "k", "hash"), true)
+			complete(t, store, "alice", "k")
+			if err := store.ReleaseIdempotencyKey(context.Background(), "alice", "k"); err != nil {
+				t.Fatal(err)
+			}
+			reserve(t, store, reservation("alice", "k", "hash"), false)
+		})
+
+		t.Run("complete keeps a completed key", func(t *testing.T) {
+			store := newStore(t)
+			reserve(t, store, reservation("alice", "k", "hash"), true)
+			complete(t, store, "alice", "k")
+			err := store.CompleteIdempotencyKey(context.Background(), domain.IdempotencyRecord{
+				Principal: "alice", Key: "k", StatusCode: 500, ExpiresAt: testTime.Add(time.Hour),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := reserve(t, store, reservation("alice", "k", "hash"), false); got.StatusCode != 201 {
+				t.Errorf("second completion replaced the response: %+v", got)
+			}
+		})
+
+		t.Run("expired keys are reserved again", func(t *testing.T) {
+			store := newStore(t)
+			reserve(t, store, reservation("alice", "k", "hash"), true)
+			later := reservation("alice", "k", "other")
+			later.CreatedAt = testTime.Add(time.Minute)
+			later.ExpiresAt = later.CreatedAt.Add(time.Minute)
+			if got := reserve(t, store, later, true); got.RequestHash != "other" || got.Completed() {
+				t.Errorf("new reservation = %+v", got)
+			}
+		})
+
+		t.Run("prune", func(t *testing.T) {
+			store := newStore(t)
+			reserve(t, store, reservation("alice", "expired", "hash"), true)
+			reserve(t, store, reservation("alice", "done", "hash"), true)
+			complete(t, store, "alice", "done")
+
+			pruned, err := store.PruneIdempotencyKeys(context.Background(), testTime.Add(time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pruned != 1 {
+				t.Errorf("pruned %d records, want 1", pruned)
+			}
+			reserve(t, store, reservation("alice", "done", "hash"), false)
+		})
+	})
+}
@@ -2,75 +2,220 @@ package repository
 
 import (
 	"context"
-	"errors"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
 )
 
-// CreateUser adds a new user to the in-memory store.
+// CreatePerson adds a new person to the in-memory store.
 func (repo *InMemoryUserRepo) CreatePerson(ctx context.Context, person domain.Person) error {
 	repo.mu.Lock()
 	defer repo.mu.Unlock()
 
 	if _, exists := repo.persons[person.Id]; exists {
-		return errors.New("user already exists")
+		return domain.ErrPersonExists
 	}
 
 	repo.persons[person.Id] = person
 	return nil
 }
 
-// GetAllUsers retrieves all users from the in-memory store with pagination.
-func (repo *InMemoryUserRepo) GetAllPersons(ctx context.Context, limit, offset int) ([]domain.Person, error) {
+// GetAllPersons retrieves the persons matching query, ordered and paged the
+// same way as the Postgres repository.
+func (repo *InMemoryUserRepo) GetAllPersons(ctx context.Context, query domain.PersonQuery) ([]domain.Person, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	order := personOrder(query.SortBy, query.SortDesc)
+	var matched []domain.Person
+	for _, person := range repo.persons {
+		if !matchesPerson(person, query.PersonFilter) {
+			continue
+		}
+		if query.Cursor != nil {
+			c := order(person, query.Cursor.Key())
+			if (query.Cursor.Before && c >= 0) || (!query.Cursor.Before && c <= 0) {
+				continue
+			}
+		}
+		matched = append(matched, person)
+	}
+	slices.SortFunc(matched, order)
+
+	switch {
+	case query.Cursor != nil && query.Cursor.Before:
+		// The page ends right before the cursor.
+		if len(matched) > query.Limit {
+			matched = matched[len(matched)-query.Limit:]
+		}
+		return matched, nil
+	case query.Cursor == nil:
+		if query.Offset >= len(matched) {
+			return nil, nil
+		}
+		matched = matched[query.Offset:]
+	}
+	if len(matched) > query.Limit {
+		matched = matched[:query.Limit]
+	}
+	return matched, nil
+}
+
+// CountPersons counts the persons matching filter.
+func (repo *InMemoryUserRepo) CountPersons(ctx context.Context, filter domain.PersonFilter) (int, error) {
 	repo.mu.RLock()
 	defer repo.mu.RUnlock()
 
-	var result []domain.Person
 	count := 0
-	for _, user := range repo.persons {
-		if count >= offset && len(result) < limit {
-			result = append(result, user)
+	for _, person := range repo.persons {
+		if matchesPerson(person, filter) {
+			count++
 		}
-		count++
 	}
-	return result, nil
+	return count, nil
 }
 
 // UpdateUser updates an existing user in the in-memory store.
-func (repo *InMemoryUserRepo) UpdatePerson(ctx context.Context, person domain.Person) error {
+func (repo *InMemoryUserRepo) UpdatePerson(ctx context.Context, person domain.Person) (*domain.Person, error) {
 	repo.mu.Lock()
 	defer repo.mu.Unlock()
 
-	if _, exists := repo.persons[person.Id]; !exists {
-		return errors.New("user not found")
+	existing, err := repo.personAtVersion(person.Id, person.Version)
+	if err != nil {
+		return nil, err
 	}
 
+	person.CreatedAt = existing.CreatedAt
+	person.Version = existing.Version + 1
 	repo.persons[person.Id] = person
-	return nil
+	return &person, nil
 }
 
-// DeleteUser removes a user from the in-memory store.
-func (repo *InMemoryUserRepo) DeletePerson(ctx context.Context, id uuid.UUID) error {
+// UpsertPerson adds person or overwrites the live person with its id.
+func (repo *InMemoryUserRepo) UpsertPerson(ctx context.Context, person domain.Person) (*domain.Person, bool, error) {
 	repo.mu.Lock()
 	defer repo.mu.Unlock()
 
-	if _, exists := repo.persons[id]; !exists {
-		return errors.New("user not found")
+	existing, exists := repo.persons[person.Id]
+	switch {
+	case exists && existing.DeletedAt != nil:
+		return nil, false, domain.ErrPersonDeleted
+	case !exists && person.Version != 0:
+		return nil, false, domain.ErrPersonNotFound
+	case exists && person.Version != 0 && existing.Version != person.Version:
+		return nil, false, domain.ErrPersonVersionMismatch
 	}
 
-	delete(repo.persons, id)
+	if exists {
+		person.CreatedAt = existing.CreatedAt
+		person.Version = existing.Version + 1
+	} else {
+		person.Version = 1
+	}
+	repo.persons[person.Id] = person
+	return &person, !exists, nil
+}
+
+// PatchPerson copies fields of person onto the stored person.
+func (repo *InMemoryUserRepo) PatchPerson(ctx context.Context, person domain.Person, fields []domain.PersonField) (*domain.Person, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	existing, err := repo.personAtVersion(person.Id, person.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range fields {
+		switch field {
+		case domain.PersonFieldName:
+			existing.Name = person.Name
+		case domain.PersonFieldAge:
+			existing.Age = person.Age
+		case domain.PersonFieldHobbies:
+			existing.Hobbies = person.Hobbies
+		}
+	}
+	existing.UpdatedAt = person.UpdatedAt
+	existing.Version++
+	repo.persons[person.Id] = existing
+	return &existing, nil
+}
+
+// DeleteUser marks a user deleted in the in-memory store.
+func (repo *InMemoryUserRepo) DeletePerson(ctx context.Context, id uuid.UUID, version int64, at time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	existing, err := repo.personAtVersion(id, version)
+	if err != nil {
+		return err
+	}
+
+	existing.DeletedAt = &at
+	existing.Version++
+	repo.persons[id] = existing
 	return nil
 }
 
+// RestorePerson clears the tombstone of a deleted person.
+func (repo *InMemoryUserRepo) RestorePerson(ctx context.Context, id uuid.UUID, at time.Time) (*domain.Person, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	existing, exists := repo.persons[id]
+	if !exists {
+		return nil, domain.ErrPersonNotFound
+	}
+	if existing.DeletedAt == nil {
+		return nil, domain.ErrPersonNotDeleted
+	}
+
+	existing.DeletedAt = nil
+	existing.UpdatedAt = at
+	existing.Version++
+	repo.persons[id] = existing
+	return &existing, nil
+}
+
+// PurgePersons drops the persons deleted before the given time.
+func (repo *InMemoryUserRepo) PurgePersons(ctx context.Context, before time.Time) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var purged int64
+	for id, person := range repo.persons {
+		if person.DeletedAt != nil && person.DeletedAt.Before(before) {
+			delete(repo.persons, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// personAtVersion returns the live stored person if version is 0 or its
+// current version. The caller must hold repo.mu.
+func (repo *InMemoryUserRepo) personAtVersion(id uuid.UUID, version int64) (domain.Person, error) {
+	existing, exists := repo.persons[id]
+	if !exists || existing.DeletedAt != nil {
+		return domain.Person{}, domain.ErrPersonNotFound
+	}
+	if version != 0 && existing.Version != version {
+		return domain.Person{}, domain.ErrPersonVersionMismatch
+	}
+	return existing, nil
+}
+
 // GetUserByID retrieves a user by their ID.
 func (repo *InMemoryUserRepo) GetPerson(ctx context.Context, userID uuid.UUID) (*domain.Person, error) {
 	repo.mu.RLock()
 	defer repo.mu.RUnlock()
 
 	user, exists := repo.persons[userID]
-	if !exists {
-		return nil, errors.New("user not found")
+	if !exists || user.DeletedAt != nil {
+		return nil, domain.ErrPersonNotFound
 	}
 	return &user, nil
 }
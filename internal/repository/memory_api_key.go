@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// CreateAPIKey stores a newly issued API key.
+func (repo *InMemoryAPIKeyStore) CreateAPIKey(ctx context.Context, key domain.APIKey) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.keys[key.Id] = key
+	return nil
+}
+
+// GetAPIKey retrieves an API key by its ID.
+func (repo *InMemoryAPIKeyStore) GetAPIKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	key, exists := repo.keys[id]
+	if !exists {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	return &key, nil
+}
+
+// GetAPIKeyByPrefix retrieves an API key by its public prefix.
+func (repo *InMemoryAPIKeyStore) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	for _, key := range repo.keys {
+		if key.Prefix == prefix {
+			return &key, nil
+		}
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+// ListAPIKeys returns the keys owned by userID, newest first.
+func (repo *InMemoryAPIKeyStore) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var result []domain.APIKey
+	for _, key := range repo.keys {
+		if key.UserID == userID {
+			result = append(result, key)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+// RevokeAPIKey marks an API key as revoked.
+func (repo *InMemoryAPIKeyStore) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	key, exists := repo.keys[id]
+	if !exists {
+		return domain.ErrAPIKeyNotFound
+	}
+	if key.RevokedAt == nil {
+		now := time.Now()
+		key.RevokedAt = &now
+		repo.keys[id] = key
+	}
+	return nil
+}
+
+// RotateAPIKey revokes oldID and stores its replacement.
+func (repo *InMemoryAPIKeyStore) RotateAPIKey(ctx context.Context, oldID uuid.UUID, next domain.APIKey) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	old, exists := repo.keys[oldID]
+	if !exists || old.RevokedAt != nil {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	now := time.Now()
+	old.RevokedAt = &now
+	repo.keys[oldID] = old
+	repo.keys[next.Id] = next
+	return nil
+}
+
+// TouchAPIKey records when an API key was last used.
+func (repo *InMemoryAPIKeyStore) TouchAPIKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	key, exists := repo.keys[id]
+	if !exists {
+		return domain.ErrAPIKeyNotFound
+	}
+	key.LastUsedAt = &usedAt
+	repo.keys[id] = key
+	return nil
+}
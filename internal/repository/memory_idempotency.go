@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// ReserveIdempotencyKey stores record unless an unexpired record holds its
+// key.
+func (repo *InMemoryIdempotencyStore) ReserveIdempotencyKey(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	id := idempotencyKey{principal: record.Principal, key: record.Key}
+	if existing, ok := repo.records[id]; ok && existing.ExpiresAt.After(record.CreatedAt) {
+		return existing, false, nil
+	}
+	repo.records[id] = record
+	return record, true, nil
+}
+
+// CompleteIdempotencyKey stores the response of a reserved record.
+func (repo *InMemoryIdempotencyStore) CompleteIdempotencyKey(ctx context.Context, record domain.IdempotencyRecord) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	id := idempotencyKey{principal: record.Principal, key: record.Key}
+	if reserved, ok := repo.records[id]; ok && !reserved.Completed() {
+		reserved.StatusCode = record.StatusCode
+		reserved.ContentType = record.ContentType
+		reserved.Body = record.Body
+		reserved.ExpiresAt = record.ExpiresAt
+		repo.records[id] = reserved
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey drops a reservation that has no response yet.
+func (repo *InMemoryIdempotencyStore) ReleaseIdempotencyKey(ctx context.Context, principal, key string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	id := idempotencyKey{principal: principal, key: key}
+	if record, ok := repo.records[id]; ok && !record.Completed() {
+		delete(repo.records, id)
+	}
+	return nil
+}
+
+// PruneIdempotencyKeys drops records that expired before now.
+func (repo *InMemoryIdempotencyStore) PruneIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var pruned int64
+	for id, record := range repo.records {
+		if !record.ExpiresAt.After(now) {
+			delete(repo.records, id)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
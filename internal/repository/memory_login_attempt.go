@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// GetLoginThrottle returns the failed-login state of key.
+func (repo *InMemoryLoginAttemptStore) GetLoginThrottle(ctx context.Context, key string) (domain.LoginThrottle, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.attempts[key], nil
+}
+
+// RecordLoginFailure counts a failed login for key.
+func (repo *InMemoryLoginAttemptStore) RecordLoginFailure(ctx context.Context, key string, now time.Time, window time.Duration) (domain.LoginThrottle, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	throttle := repo.attempts[key]
+	if throttle.LastFailureAt.Before(now.Add(-window)) {
+		throttle = domain.LoginThrottle{}
+	}
+	throttle.Failures++
+	throttle.LastFailureAt = now
+	repo.attempts[key] = throttle
+	return throttle, nil
+}
+
+// LockLogin blocks logins for key until until.
+func (repo *InMemoryLoginAttemptStore) LockLogin(ctx context.Context, key string, until time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	throttle := repo.attempts[key]
+	throttle.LockedUntil = until
+	repo.attempts[key] = throttle
+	return nil
+}
+
+// ResetLoginFailures forgets the failures of key.
+func (repo *InMemoryLoginAttemptStore) ResetLoginFailures(ctx context.Context, key string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	delete(repo.attempts, key)
+	return nil
+}
+
+// PruneLoginAttempts drops stale, unlocked entries.
+func (repo *InMemoryLoginAttemptStore) PruneLoginAttempts(ctx context.Context, before time.Time) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var pruned int64
+	for key, throttle := range repo.attempts {
+		if throttle.LastFailureAt.Before(before) && throttle.LockedUntil.Before(before) {
+			delete(repo.attempts, key)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// SaveTOTP stores a pending TOTP enrollment.
+func (repo *InMemoryMFAStore) SaveTOTP(ctx context.Context, enrollment domain.TOTPEnrollment) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.totp[enrollment.UserID] = enrollment
+	return nil
+}
+
+// GetTOTP retrieves the TOTP enrollment of userID.
+func (repo *InMemoryMFAStore) GetTOTP(ctx context.Context, userID uuid.UUID) (*domain.TOTPEnrollment, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	enrollment, exists := repo.totp[userID]
+	if !exists {
+		return nil, domain.ErrTOTPNotFound
+	}
+	return &enrollment, nil
+}
+
+// ConfirmTOTP activates the enrollment of userID.
+func (repo *InMemoryMFAStore) ConfirmTOTP(ctx context.Context, userID uuid.UUID, step int64, codeHashes []string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	enrollment, exists := repo.totp[userID]
+	if !exists {
+		return domain.ErrTOTPNotFound
+	}
+	now := time.Now().UTC()
+	enrollment.ConfirmedAt = &now
+	enrollment.LastUsedStep = step
+	repo.totp[userID] = enrollment
+	repo.setRecoveryCodes(userID, codeHashes)
+	return nil
+}
+
+// UseTOTPStep records step as the last accepted code of userID.
+func (repo *InMemoryMFAStore) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	enrollment, exists := repo.totp[userID]
+	if !exists {
+		return domain.ErrTOTPNotFound
+	}
+	if step <= enrollment.LastUsedStep {
+		return domain.ErrTOTPStepUsed
+	}
+	enrollment.LastUsedStep = step
+	repo.totp[userID] = enrollment
+	return nil
+}
+
+// DeleteTOTP removes the enrollment and recovery codes of userID.
+func (repo *InMemoryMFAStore) DeleteTOTP(ctx context.Context, userID uuid.UUID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	delete(repo.totp, userID)
+	delete(repo.recoveryCodes, userID)
+	return nil
+}
+
+// ReplaceRecoveryCodes swaps the recovery codes of userID for codeHashes.
+func (repo *InMemoryMFAStore) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.setRecoveryCodes(userID, codeHashes)
+	return nil
+}
+
+// UseRecoveryCode consumes one of userID's recovery codes.
+func (repo *InMemoryMFAStore) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	codes := repo.recoveryCodes[userID]
+	if used, exists := codes[codeHash]; !exists || used {
+		return domain.ErrRecoveryCodeInvalid
+	}
+	codes[codeHash] = true
+	return nil
+}
+
+func (repo *InMemoryMFAStore) setRecoveryCodes(userID uuid.UUID, codeHashes []string) {
+	codes := make(map[string]bool, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes[hash] = false
+	}
+	repo.recoveryCodes[userID] = codes
+}
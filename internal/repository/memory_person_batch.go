@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// CreatePersons adds persons to the in-memory store.
+func (repo *InMemoryUserRepo) CreatePersons(ctx context.Context, persons []domain.Person, atomic bool) ([]domain.BatchResult, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	results := make([]domain.BatchResult, len(persons))
+	for i, person := range persons {
+		results[i].Person = person
+		if _, exists := repo.persons[person.Id]; exists {
+			results[i].Err = domain.ErrPersonExists
+		}
+	}
+	repo.applyBatch(results, atomic)
+	return results, nil
+}
+
+// UpdatePersons overwrites persons in the in-memory store.
+func (repo *InMemoryUserRepo) UpdatePersons(ctx context.Context, persons []domain.Person, atomic bool) ([]domain.BatchResult, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	results := make([]domain.BatchResult, len(persons))
+	for i, person := range persons {
+		existing, err := repo.personAtVersion(person.Id, person.Version)
+		if err != nil {
+			results[i] = domain.BatchResult{Person: person, Err: err}
+			continue
+		}
+		person.CreatedAt = existing.CreatedAt
+		person.Version = existing.Version + 1
+		results[i].Person = person
+	}
+	repo.applyBatch(results, atomic)
+	return results, nil
+}
+
+// DeletePersons marks persons deleted in the in-memory store.
+func (repo *InMemoryUserRepo) DeletePersons(ctx context.Context, persons []domain.Person, at time.Time, atomic bool) ([]domain.BatchResult, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	results := make([]domain.BatchResult, len(persons))
+	for i, person := range persons {
+		existing, err := repo.personAtVersion(person.Id, person.Version)
+		if err != nil {
+			results[i] = domain.BatchResult{Person: person, Err: err}
+			continue
+		}
+		existing.DeletedAt = &at
+		existing.Version++
+		results[i].Person = existing
+	}
+	repo.applyBatch(results, atomic)
+	return results, nil
+}
+
+// applyBatch stores the persons of the successful results, unless the
+// batch has to be aborted. The caller must hold repo.mu.
+func (repo *InMemoryUserRepo) applyBatch(results []domain.BatchResult, atomic bool) {
+	if abortBatch(results, atomic) {
+		return
+	}
+	for _, result := range results {
+		if result.Err == nil {
+			repo.persons[result.Person.Id] = result.Person
+		}
+	}
+}
+
+// abortBatch reports whether an atomic batch with a failed item must be
+// rolled back, in which case its other items are marked aborted.
+func abortBatch(results []domain.BatchResult, atomic bool) bool {
+	if !atomic {
+		return false
+	}
+	failed := false
+	for _, result := range results {
+		if result.Err != nil {
+			failed = true
+			break
+		}
+	}
+	if !failed {
+		return false
+	}
+	for i := range results {
+		if results[i].Err == nil {
+			results[i].Err = domain.ErrBatchAborted
+		}
+	}
+	return true
+}
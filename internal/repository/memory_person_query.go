@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// matchesPerson applies filter the way PostgresUserRepo's WHERE clause
+// does.
+func matchesPerson(person domain.Person, filter domain.PersonFilter) bool {
+	if (person.DeletedAt != nil) != filter.Deleted {
+		return false
+	}
+	name := strings.ToLower(person.Name)
+	if filter.NameContains != "" && !strings.Contains(name, strings.ToLower(filter.NameContains)) {
+		return false
+	}
+	if filter.NamePrefix != "" && !strings.HasPrefix(name, strings.ToLower(filter.NamePrefix)) {
+		return false
+	}
+	if filter.MinAge != nil && person.Age < *filter.MinAge {
+		return false
+	}
+	if filter.MaxAge != nil && person.Age > *filter.MaxAge {
+		return false
+	}
+	for _, hobby := range filter.Hobbies {
+		if !slices.Contains(person.Hobbies, hobby) {
+			return false
+		}
+	}
+
+	if terms := domain.SearchTerms(filter.Search); len(terms) > 0 {
+		words := domain.PersonSearchTerms(person)
+		for _, term := range terms {
+			if !slices.ContainsFunc(words, func(word string) bool {
+				return strings.HasPrefix(word, term)
+			}) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// personOrder compares persons by field and then id, matching the ORDER BY
+// of PostgresUserRepo.
+func personOrder(field domain.PersonSortField, desc bool) func(a, b domain.Person) int {
+	return func(a, b domain.Person) int {
+		c := comparePersons(a, b, field)
+		if c == 0 {
+			c = bytes.Compare(a.Id[:], b.Id[:])
+		}
+		if desc {
+			return -c
+		}
+		return c
+	}
+}
+
+func comparePersons(a, b domain.Person, field domain.PersonSortField) int {
+	switch field {
+	case domain.SortByName:
+		if c := strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	case domain.SortByAge:
+		return a.Age - b.Age
+	case domain.SortByUpdatedAt:
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	default:
+		return a.CreatedAt.Compare(b.CreatedAt)
+	}
+}
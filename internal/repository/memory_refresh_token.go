@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// CreateRefreshToken stores a newly issued refresh token.
+func (repo *InMemoryRefreshTokenStore) CreateRefreshToken(ctx context.Context, token domain.RefreshToken) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.tokens[token.Id] = token
+	return nil
+}
+
+// GetRefreshTokenByHash looks up a refresh token by the hash of its value.
+func (repo *InMemoryRefreshTokenStore) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	for _, token := range repo.tokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, domain.ErrRefreshTokenNotFound
+}
+
+// RotateRefreshToken revokes oldID in favour of next.
+func (repo *InMemoryRefreshTokenStore) RotateRefreshToken(ctx context.Context, oldID uuid.UUID, next domain.RefreshToken) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	old, exists := repo.tokens[oldID]
+	if !exists {
+		return domain.ErrRefreshTokenNotFound
+	}
+	if old.RevokedAt != nil {
+		return domain.ErrRefreshTokenRevoked
+	}
+
+	now := time.Now()
+	old.RevokedAt = &now
+	old.ReplacedBy = &next.Id
+	repo.tokens[oldID] = old
+	repo.tokens[next.Id] = next
+	return nil
+}
+
+// RevokeRefreshTokenFamily revokes every token rotated from the same login.
+func (repo *InMemoryRefreshTokenStore) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	now := time.Now()
+	for id, token := range repo.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			repo.tokens[id] = token
+		}
+	}
+	return nil
+}
+
+// RevokeUserRefreshTokens revokes every refresh token of userID.
+func (repo *InMemoryRefreshTokenStore) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	now := time.Now()
+	for id, token := range repo.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			repo.tokens[id] = token
+		}
+	}
+	return nil
+}
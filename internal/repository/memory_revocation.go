@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RevokeToken records a single revoked token ID.
+func (repo *InMemoryRevocationStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.tokens[jti] = expiresAt
+	return nil
+}
+
+// RevokeUserTokens revokes every token of userID issued before before.
+func (repo *InMemoryRevocationStore) RevokeUserTokens(ctx context.Context, userID uuid.UUID, before, expiresAt time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.users[userID] = userRevocation{revokedBefore: before, expiresAt: expiresAt}
+	return nil
+}
+
+// IsRevoked reports whether the token was revoked individually or through
+// its user.
+func (repo *InMemoryRevocationStore) IsRevoked(ctx context.Context, jti string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	if _, revoked := repo.tokens[jti]; revoked {
+		return true, nil
+	}
+	if rev, ok := repo.users[userID]; ok && issuedAt.Before(rev.revokedBefore) {
+		return true, nil
+	}
+	return false, nil
+}
+
+// PruneRevocations drops entries whose tokens have expired.
+func (repo *InMemoryRevocationStore) PruneRevocations(ctx context.Context, now time.Time) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var pruned int64
+	for jti, expiresAt := range repo.tokens {
+		if !expiresAt.After(now) {
+			delete(repo.tokens, jti)
+			pruned++
+		}
+	}
+	for userID, rev := range repo.users {
+		if !rev.expiresAt.After(now) {
+			delete(repo.users, userID)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// CreateUser adds a new account, rejecting emails that are already taken.
+func (repo *InMemoryUserStore) CreateUser(ctx context.Context, user domain.User) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, existing := range repo.users {
+		if strings.EqualFold(existing.Email, user.Email) {
+			return domain.ErrEmailTaken
+		}
+	}
+
+	repo.users[user.Id] = user
+	return nil
+}
+
+// GetUserByID retrieves an account by its ID.
+func (repo *InMemoryUserStore) GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	user, exists := repo.users[id]
+	if !exists {
+		return nil, domain.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+// GetUserByEmail retrieves an account by its email, ignoring case.
+func (repo *InMemoryUserStore) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	for _, user := range repo.users {
+		if strings.EqualFold(user.Email, email) {
+			return &user, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+// UpdatePassword replaces the password hash of an existing account.
+func (repo *InMemoryUserStore) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	user, exists := repo.users[id]
+	if !exists {
+		return domain.ErrUserNotFound
+	}
+
+	user.PasswordHash = passwordHash
+	user.UpdatedAt = time.Now()
+	repo.users[id] = user
+	return nil
+}
+
+// UpdateRole changes the role of an existing account.
+func (repo *InMemoryUserStore) UpdateRole(ctx context.Context, id uuid.UUID, role domain.Role) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	user, exists := repo.users[id]
+	if !exists {
+		return domain.ErrUserNotFound
+	}
+
+	user.Role = role
+	user.UpdatedAt = time.Now()
+	repo.users[id] = user
+	return nil
+}
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS persons (
+    id         UUID PRIMARY KEY,
+    name       TEXT        NOT NULL,
+    age        INTEGER     NOT NULL,
+    hobbies    JSONB       NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS persons_created_at_idx ON persons (created_at DESC);
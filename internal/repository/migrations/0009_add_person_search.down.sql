@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS persons_updated_at_idx;
+DROP INDEX IF EXISTS persons_name_idx;
+DROP INDEX IF EXISTS persons_age_idx;
+DROP INDEX IF EXISTS persons_hobbies_idx;
+DROP INDEX IF EXISTS persons_name_trgm_idx;
+DROP INDEX IF EXISTS persons_search_idx;
+
+ALTER TABLE persons DROP COLUMN IF EXISTS search;
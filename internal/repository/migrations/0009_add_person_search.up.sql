@@ -0,0 +1,13 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE persons ADD COLUMN IF NOT EXISTS search tsvector
+    GENERATED ALWAYS AS (
+        to_tsvector('simple', name) || jsonb_to_tsvector('simple', hobbies, '["string"]')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS persons_search_idx ON persons USING GIN (search);
+CREATE INDEX IF NOT EXISTS persons_name_trgm_idx ON persons USING GIN (lower(name) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS persons_hobbies_idx ON persons USING GIN (hobbies jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS persons_age_idx ON persons (age, id);
+CREATE INDEX IF NOT EXISTS persons_name_idx ON persons ((lower(name)) COLLATE "C", name COLLATE "C", id);
+CREATE INDEX IF NOT EXISTS persons_updated_at_idx ON persons (updated_at, id);
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS persons_created_at_idx ON persons (created_at DESC);
+DROP INDEX IF EXISTS persons_created_at_id_idx;
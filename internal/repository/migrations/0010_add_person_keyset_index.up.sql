@@ -0,0 +1,4 @@
+-- Keyset pagination orders by (created_at, id); this index serves both
+-- directions and replaces the created_at-only one.
+CREATE INDEX IF NOT EXISTS persons_created_at_id_idx ON persons (created_at, id);
+DROP INDEX IF EXISTS persons_created_at_idx;
@@ -0,0 +1,3 @@
+-- Optimistic concurrency: every write bumps the version, and conditional
+-- writes only apply when the version a client read is still current.
+ALTER TABLE persons ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
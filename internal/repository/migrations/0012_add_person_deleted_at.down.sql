@@ -0,0 +1,12 @@
+-- Rolling back would make deleted persons live again or lose them, so the
+-- rollback is refused until the purge, or an operator, has removed them.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM persons WHERE deleted_at IS NOT NULL) THEN
+        RAISE EXCEPTION 'persons has deleted rows; purge or restore them before rolling back';
+    END IF;
+END
+$$;
+
+DROP INDEX IF EXISTS persons_deleted_at_idx;
+ALTER TABLE persons DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,5 @@
+-- Deleting a person only records a tombstone; rows are hard-deleted by the
+-- purge once they have been deleted for longer than the retention period.
+ALTER TABLE persons ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS persons_deleted_at_idx ON persons (deleted_at) WHERE deleted_at IS NOT NULL;
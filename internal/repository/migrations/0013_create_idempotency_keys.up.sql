@@ -0,0 +1,16 @@
+-- Requests sent with an Idempotency-Key. status_code is NULL while the
+-- request is in progress; expires_at first bounds the reservation, then how
+-- long the stored response is replayed.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    principal    TEXT        NOT NULL,
+    key          TEXT        NOT NULL,
+    request_hash TEXT        NOT NULL,
+    status_code  INTEGER,
+    content_type TEXT        NOT NULL DEFAULT '',
+    body         BYTEA,
+    created_at   TIMESTAMPTZ NOT NULL,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (principal, key)
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
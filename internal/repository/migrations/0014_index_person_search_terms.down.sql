@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS persons_search_idx;
+ALTER TABLE persons DROP COLUMN IF EXISTS search;
+ALTER TABLE persons DROP COLUMN IF EXISTS search_terms;
+
+ALTER TABLE persons ADD COLUMN IF NOT EXISTS search tsvector
+    GENERATED ALWAYS AS (
+        to_tsvector('simple', name) || jsonb_to_tsvector('simple', hobbies, '["string"]')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS persons_search_idx ON persons USING GIN (search);
@@ -0,0 +1,19 @@
+-- The search vector is built from the words the application computes with
+-- domain.PersonSearchTerms, so Postgres finds exactly the persons the
+-- in-memory repository does. to_tsvector would keep "3.5" or "well-known"
+-- as single tokens and lowercase by the database locale.
+DROP INDEX IF EXISTS persons_search_idx;
+ALTER TABLE persons DROP COLUMN IF EXISTS search;
+
+ALTER TABLE persons ADD COLUMN IF NOT EXISTS search_terms TEXT[] NOT NULL DEFAULT '{}';
+
+-- Existing rows are split the same way in SQL; every write replaces the
+-- terms with the application's.
+UPDATE persons SET search_terms = array_remove(regexp_split_to_array(
+    lower(name || ' ' || coalesce((SELECT string_agg(hobby, ' ') FROM jsonb_array_elements_text(hobbies) AS hobby), '')),
+    '[^[:alnum:]]+'), '');
+
+ALTER TABLE persons ADD COLUMN search tsvector
+    GENERATED ALWAYS AS (array_to_tsvector(search_terms)) STORED;
+
+CREATE INDEX IF NOT EXISTS persons_search_idx ON persons USING GIN (search);
@@ -0,0 +1,90 @@
+package migrations
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0010_add_index.up.sql":      {Data: []byte("CREATE INDEX i ON t (c);")},
+		"0010_add_index.down.sql":    {Data: []byte("DROP INDEX i;")},
+		"0002_create_table.up.sql":   {Data: []byte("CREATE TABLE t (c INT);")},
+		"0003_no_down_script.up.sql": {Data: []byte("SELECT 1;")},
+		"0002_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+		"0001_first_of_all.up.sql":   {Data: []byte("SELECT 0;")},
+		"0001_first_of_all.down.sql": {Data: []byte("SELECT 0;")},
+	}
+	migrations, err := load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		version int64
+		name    string
+		down    string
+	}{
+		{1, "first_of_all", "SELECT 0;"},
+		{2, "create_table", "DROP TABLE t;"},
+		{3, "no_down_script", ""},
+		{10, "add_index", "DROP INDEX i;"},
+	}
+	if len(migrations) != len(want) {
+		t.Fatalf("loaded %d migrations, want %d", len(migrations), len(want))
+	}
+	for i, mig := range migrations {
+		if mig.Version != want[i].version || mig.Name != want[i].name || mig.Down != want[i].down {
+			t.Errorf("migration %d = %d %s down %q, want %d %s down %q", i, mig.Version, mig.Name, mig.Down, want[i].version, want[i].name, want[i].down)
+		}
+		if len(mig.Checksum) != 64 {
+			t.Errorf("migration %d has checksum %q", mig.Version, mig.Checksum)
+		}
+	}
+	if migrations[0].Checksum == migrations[1].Checksum {
+		t.Error("different up scripts have the same checksum")
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+	}{
+		{name: "no direction", files: []string{"0001_init.sql"}},
+		{name: "no name", files: []string{"0001.up.sql"}},
+		{name: "version not a number", files: []string{"first_init.up.sql"}},
+		{name: "version zero", files: []string{"0000_init.up.sql"}},
+		{name: "version shared by two names", files: []string{"0001_init.up.sql", "0001_other.up.sql"}},
+		{name: "down without up", files: []string{"0001_init.down.sql"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			for _, name := range tt.files {
+				fsys[name] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+			}
+			if _, err := load(fsys); !errors.Is(err, ErrInvalidFileName) {
+				t.Errorf("load(%v) error = %v, want ErrInvalidFileName", tt.files, err)
+			}
+		})
+	}
+}
+
+// TestEmbeddedMigrations checks the migrations shipped in the binary: they
+// load, their versions have no gaps and every one can be rolled back.
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := load(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, mig := range migrations {
+		if mig.Version != int64(i+1) {
+			t.Errorf("migration %d_%s follows version %d", mig.Version, mig.Name, i)
+		}
+		if mig.Down == "" {
+			t.Errorf("migration %d_%s has no down script", mig.Version, mig.Name)
+		}
+	}
+}
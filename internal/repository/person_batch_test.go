@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/ports"
+)
+
+// batchFixture is a store holding a live person at version 1 and a deleted
+// one, with a batch of three persons around them.
+type batchFixture struct {
+	repo    ports.PersonRepository
+	live    domain.Person
+	deleted domain.Person
+}
+
+func newBatchFixture(t *testing.T, newRepo func(t *testing.T) ports.PersonRepository) batchFixture {
+	repo := newRepo(t)
+	return batchFixture{
+		repo:    repo,
+		live:    seedPerson(t, repo, "Live", 30),
+		deleted: seedDeletedPerson(t, repo),
+	}
+}
+
+func newBatchPerson(name string) domain.Person {
+	return domain.Person{Id: uuid.New(), Name: name, Age: 1, Hobbies: []string{}, CreatedAt: testTime, UpdatedAt: testTime, Version: 1}
+}
+
+// checkBatchErrs compares the errors of results with want, in order.
+func checkBatchErrs(t *testing.T, results []domain.BatchResult, want []error) {
+	t.Helper()
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, result := range results {
+		if want[i] == nil && result.Err != nil || want[i] != nil && !errors.Is(result.Err, want[i]) {
+			t.Errorf("result %d error = %v, want %v", i, result.Err, want[i])
+		}
+	}
+}
+
+// exists reports whether the live person id is stored.
+func exists(t *testing.T, repo ports.PersonRepository, id uuid.UUID) bool {
+	t.Helper()
+	_, err := repo.GetPerson(context.Background(), id)
+	if err != nil && !errors.Is(err, domain.ErrPersonNotFound) {
+		t.Fatal(err)
+	}
+	return err == nil
+}
+
+func TestCreatePersons(t *testing.T) {
+	withAdapters(t, personRepositories, func(t *testing.T, newRepo func(t *testing.T) ports.PersonRepository) {
+		for _, atomic := range []bool{false, true} {
+			name := map[bool]string{false: "best effort", true: "atomic"}[atomic]
+			t.Run(name, func(t *testing.T) {
+				f := newBatchFixture(t, newRepo)
+				a, b := newBatchPerson("Al"), newBatchPerson("Bo")
+				taken := newBatchPerson("Taken")
+				taken.Id = f.deleted.Id
+
+				results, err := f.repo.CreatePersons(context.Background(), []domain.Person{a, taken, b}, atomic)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if atomic {
+					checkBatchErrs(t, results, []error{domain.ErrBatchAborted, domain.ErrPersonExists, domain.ErrBatchAborted})
+				} else {
+					checkBatchErrs(t, results, []error{nil, domain.ErrPersonExists, nil})
+				}
+				for _, person := range []domain.Person{a, b} {
+					if got := exists(t, f.repo, person.Id); got == atomic {
+						t.Errorf("%s stored = %v, want %v", person.Name, got, !atomic)
+					}
+				}
+			})
+		}
+	})
+}
+
+func TestUpdatePersons(t *testing.T) {
+	withAdapters(t, personRepositories, func(t *testing.T, newRepo func(t *testing.T) ports.PersonRepository) {
+		for _, atomic := range []bool{false, true} {
+			name := map[bool]string{false: "best effort", true: "atomic"}[atomic]
+			t.Run(name, func(t *testing.T) {
+				ctx := context.Background()
+				f := newBatchFixture(t, newRepo)
+				other := seedPerson(t, f.repo, "Other", 20)
+
+				update := f.live
+				update.Name = "Updated"
+				stale := other
+				stale.Version = 7
+				results, err := f.repo.UpdatePersons(ctx, []domain.Person{update, stale, f.deleted, newBatchPerson("Missing")}, atomic)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if atomic {
+					checkBatchErrs(t, results, []error{domain.ErrBatchAborted, domain.ErrPersonVersionMismatch, domain.ErrPersonNotFound, domain.ErrPersonNotFound})
+				} else {
+					checkBatchErrs(t, results, []error{nil, domain.ErrPersonVersionMismatch, domain.ErrPersonNotFound, domain.ErrPersonNotFound})
+					if results[0].Person.Version != 2 || !results[0].Person.CreatedAt.Equal(testTime) {
+						t.Errorf("updated person = %+v, want version 2 created at %v", results[0].Person, testTime)
+					}
+				}
+
+				got, err := f.repo.GetPerson(ctx, f.live.Id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if wantName := map[bool]string{false: "Updated", true: "Live"}[atomic]; got.Name != wantName {
+					t.Errorf("stored name = %q, want %q", got.Name, wantName)
+				}
+			})
+		}
+	})
+}
+
+func TestDeletePersons(t *testing.T) {
+	withAdapters(t, personRepositories, func(t *testing.T, newRepo func(t *testing.T) ports.PersonRepository) {
+		for _, atomic := range []bool{false, true} {
+			name := map[bool]string{false: "best effort", true: "atomic"}[atomic]
+			t.Run(name, func(t *testing.T) {
+				f := newBatchFixture(t, newRepo)
+				stale := seedPerson(t, f.repo, "Stale", 20)
+				stale.Version = 3
+
+				results, err := f.repo.DeletePersons(context.Background(), []domain.Person{{Id: f.live.Id}, stale, {Id: f.deleted.Id}}, testTime, atomic)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if atomic {
+					checkBatchErrs(t, results, []error{domain.ErrBatchAborted, domain.ErrPersonVersionMismatch, domain.ErrPersonNotFound})
+				} else {
+					checkBatchErrs(t, results, []error{nil, domain.ErrPersonVersionMismatch, domain.ErrPersonNotFound})
+				}
+				if got := exists(t, f.repo, f.live.Id); got != atomic {
+					t.Errorf("live person still stored = %v, want %v", got, atomic)
+				}
+				if !exists(t, f.repo, stale.Id) {
+					t.Error("person at a stale version was deleted")
+				}
+			})
+		}
+	})
+}
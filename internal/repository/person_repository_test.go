@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/ports"
+)
+
+// testTime is a time that survives a round trip through Postgres.
+var testTime = time.Date(2024, 5, 17, 9, 30, 0, 0, time.UTC)
+
+// seedPerson creates a person at version 1 and returns it.
+func seedPerson(t *testing.T, repo ports.PersonRepository, name string, age int) domain.Person {
+	t.Helper()
+	person := domain.Person{
+		Id:        uuid.New(),
+		Name:      name,
+		Age:       age,
+		Hobbies:   []string{},
+		CreatedAt: testTime,
+		UpdatedAt: testTime,
+		Version:   1,
+	}
+	if err := repo.CreatePerson(context.Background(), person); err != nil {
+		t.Fatal(err)
+	}
+	return person
+}
+
+// seedDeletedPerson creates a person and deletes it, leaving it at version
+// 2.
+func seedDeletedPerson(t *testing.T, repo ports.PersonRepository) domain.Person {
+	t.Helper()
+	person := seedPerson(t, repo, "Deleted", 40)
+	if err := repo.DeletePerson(context.Background(), person.Id, 0, testTime.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	return person
+}
+
+func TestUpsertPerson(t *testing.T) {
+	later := testTime.Add(time.Hour)
+	tests := []struct {
+		name string
+		// target picks the id to write: "new", "live" or "deleted".
+		target      string
+		version     int64
+		wantErr     error
+		wantCreated bool
+		wantVersion int64
+	}{
+		{name: "create", target: "new", wantCreated: true, wantVersion: 1},
+		{name: "overwrite", target: "live", wantVersion: 2},
+		{name: "overwrite at version", target: "live", version: 1, wantVersion: 2},
+		{name: "stale version", target: "live", version: 5, wantErr: domain.ErrPersonVersionMismatch},
+		{name: "version never creates", target: "new", version: 1, wantErr: domain.ErrPersonNotFound},
+		{name: "deleted", target: "deleted", wantErr: domain.ErrPersonDeleted},
+		{name: "deleted at version", target: "deleted", version: 2, wantErr: domain.ErrPersonDeleted},
+	}
+	withAdapters(t, personRepositories, func(t *testing.T, newRepo func(t *testing.T) ports.PersonRepository) {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				repo := newRepo(t)
+				live := seedPerson(t, repo, "Live", 30)
+				deleted := seedDeletedPerson(t, repo)
+				id := map[string]uuid.UUID{"new": uuid.New(), "live": live.Id, "deleted": deleted.Id}[tt.target]
+
+				stored, created, err := repo.UpsertPerson(ctx, domain.Person{
+					Id:        id,
+					Name:      "Written",
+					Age:       7,
+					Hobbies:   []string{"chess"},
+					CreatedAt: later,
+					UpdatedAt: later,
+					Version:   tt.version,
+				})
+				if tt.wantErr != nil {
+					if !errors.Is(err, tt.wantErr) {
+						t.Fatalf("UpsertPerson error = %v, want %v", err, tt.wantErr)
+					}
+					if got, err := repo.GetPerson(ctx, live.Id); err != nil || got.Version != 1 || got.Name != "Live" {
+						t.Errorf("failed upsert changed the live person: %+v, %v", got, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("UpsertPerson: %v", err)
+				}
+
+				if created != tt.wantCreated || stored.Version != tt.wantVersion {
+					t.Errorf("UpsertPerson = version %d, created %v; want version %d, created %v", stored.Version, created, tt.wantVersion, tt.wantCreated)
+				}
+				wantCreatedAt := testTime
+				if tt.wantCreated {
+					wantCreatedAt = later
+				}
+				if !stored.CreatedAt.Equal(wantCreatedAt) || !stored.UpdatedAt.Equal(later) {
+					t.Errorf("UpsertPerson stored created %v, updated %v; want %v, %v", stored.CreatedAt, stored.UpdatedAt, wantCreatedAt, later)
+				}
+
+				got, err := repo.GetPerson(ctx, id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Name != "Written" || got.Age != 7 || !slices.Equal(got.Hobbies, []string{"chess"}) || got.Version != tt.wantVersion {
+					t.Errorf("GetPerson after upsert = %+v", got)
+				}
+			})
+		}
+	})
+}
+
+func TestConditionalPersonWrites(t *testing.T) {
+	withAdapters(t, personRepositories, func(t *testing.T, newRepo func(t *testing.T) ports.PersonRepository) {
+		ctx := context.Background()
+		repo := newRepo(t)
+		person := seedPerson(t, repo, "Al", 3)
+
+		person.Name = "Bo"
+		person.Version = 1
+		updated, err := repo.UpdatePerson(ctx, person)
+		if err != nil || updated.Version != 2 {
+			t.Fatalf("UpdatePerson at version 1 = %+v, %v; want version 2", updated, err)
+		}
+		if _, err := repo.UpdatePerson(ctx, person); !errors.Is(err, domain.ErrPersonVersionMismatch) {
+			t.Errorf("UpdatePerson at stale version: error = %v, want ErrPersonVersionMismatch", err)
+		}
+		if err := repo.DeletePerson(ctx, person.Id, 1, testTime); !errors.Is(err, domain.ErrPersonVersionMismatch) {
+			t.Errorf("DeletePerson at stale version: error = %v, want ErrPersonVersionMismatch", err)
+		}
+		if err := repo.DeletePerson(ctx, person.Id, 2, testTime); err != nil {
+			t.Fatalf("DeletePerson at version 2: %v", err)
+		}
+
+		if _, err := repo.GetPerson(ctx, person.Id); !errors.Is(err, domain.ErrPersonNotFound) {
+			t.Errorf("GetPerson of a deleted person: error = %v, want ErrPersonNotFound", err)
+		}
+		person.Version = 0
+		if _, err := repo.UpdatePerson(ctx, person); !errors.Is(err, domain.ErrPersonNotFound) {
+			t.Errorf("UpdatePerson of a deleted person: error = %v, want ErrPersonNotFound", err)
+		}
+
+		restored, err := repo.RestorePerson(ctx, person.Id, testTime)
+		if err != nil || restored.Version != 4 || restored.DeletedAt != nil {
+			t.Fatalf("RestorePerson = %+v, %v; want live at version 4", restored, err)
+		}
+		if _, err := repo.RestorePerson(ctx, person.Id, testTime); !errors.Is(err, domain.ErrPersonNotDeleted) {
+			t.Errorf("RestorePerson of a live person: error = %v, want ErrPersonNotDeleted", err)
+		}
+		if _, err := repo.RestorePerson(ctx, uuid.New(), testTime); !errors.Is(err, domain.ErrPersonNotFound) {
+			t.Errorf("RestorePerson of a missing person: error = %v, want ErrPersonNotFound", err)
+		}
+	})
+}
+
+func TestPersonSearch(t *testing.T) {
+	withAdapters(t, personRepositories, func(t *testing.T, newRepo func(t *testing.T) ports.PersonRepository) {
+		ctx := context.Background()
+		repo := newRepo(t)
+		for _, seed := range []struct {
+			name    string
+			hobbies []string
+		}{
+			{"Anne-Marie", []string{"Python 3.5"}},
+			{"ÉLODIE", []string{"rock-climbing"}},
+			{"Bob", []string{"C++", "K2 trekking"}},
+		} {
+			person := newBatchPerson(seed.name)
+			person.Hobbies = seed.hobbies
+			if err := repo.CreatePerson(ctx, person); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		search := func(t *testing.T, q string) []string {
+			t.Helper()
+			persons, err := repo.GetAllPersons(ctx, domain.PersonQuery{
+				PersonFilter: domain.PersonFilter{Search: q},
+				SortBy:       domain.SortByName,
+				Limit:        10,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, person := range persons {
+				names = append(names, person.Name)
+			}
+			return names
+		}
+
+		tests := []struct {
+			q    string
+			want []string
+		}{
+			{"3.5", []string{"Anne-Marie"}},
+			{"5", []string{"Anne-Marie"}},
+			{"python 3", []string{"Anne-Marie"}},
+			{"anne-marie", []string{"Anne-Marie"}},
+			{"marie", []string{"Anne-Marie"}},
+			{"annemarie", nil},
+			{"rock-climbing", []string{"ÉLODIE"}},
+			{"climb", []string{"ÉLODIE"}},
+			{"élodie", []string{"ÉLODIE"}},
+			{"ÉLO", []string{"ÉLODIE"}},
+			{"elodie", nil},
+			{"c++", []string{"Bob", "ÉLODIE"}},
+			{"k2", []string{"Bob"}},
+			{"2", nil},
+			{"--", []string{"Anne-Marie", "Bob", "ÉLODIE"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.q, func(t *testing.T) {
+				if got := search(t, tt.q); !slices.Equal(got, tt.want) {
+					t.Errorf("search %q = %q, want %q", tt.q, got, tt.want)
+				}
+			})
+		}
+
+		t.Run("reindexed on update", func(t *testing.T) {
+			persons, err := repo.GetAllPersons(ctx, domain.PersonQuery{PersonFilter: domain.PersonFilter{Search: "bob"}, Limit: 10})
+			if err != nil || len(persons) != 1 {
+				t.Fatalf("GetAllPersons = %v, %v; want Bob", persons, err)
+			}
+			person := persons[0]
+			person.Name = "Bo-Jackson"
+			person.Hobbies = []string{"v8.1"}
+			if _, err := repo.UpdatePerson(ctx, person); err != nil {
+				t.Fatal(err)
+			}
+			if got := search(t, "jackson 1"); !slices.Equal(got, []string{"Bo-Jackson"}) {
+				t.Errorf("search after update = %q, want [Bo-Jackson]", got)
+			}
+			if got := search(t, "k2"); got != nil {
+				t.Errorf("search for a removed hobby = %q, want none", got)
+			}
+		})
+	})
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/ports"
+	"github.com/lib/pq"
+)
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+type PostgresAPIKeyStore struct {
+	db *sql.DB
+}
+
+func NewPostgresAPIKeyStore(db *sql.DB) ports.APIKeyRepository {
+	return &PostgresAPIKeyStore{db: db}
+}
+
+func (repo *PostgresAPIKeyStore) CreateAPIKey(ctx context.Context, key domain.APIKey) error {
+	return insertAPIKey(ctx, repo.db, key)
+}
+
+func (repo *PostgresAPIKeyStore) GetAPIKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id=$1`
+	return scanAPIKey(repo.db.QueryRowContext(ctx, query, id))
+}
+
+func (repo *PostgresAPIKeyStore) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE prefix=$1`
+	return scanAPIKey(repo.db.QueryRowContext(ctx, query, prefix))
+}
+
+func (repo *PostgresAPIKeyStore) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id=$1 ORDER BY created_at DESC`
+	rows, err := repo.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []domain.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return keys, nil
+}
+
+func (repo *PostgresAPIKeyStore) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE api_keys SET revoked_at=COALESCE(revoked_at, NOW()) WHERE id=$1`
+	result, err := repo.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (repo *PostgresAPIKeyStore) RotateAPIKey(ctx context.Context, oldID uuid.UUID, next domain.APIKey) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE api_keys SET revoked_at=NOW() WHERE id=$1 AND revoked_at IS NULL`
+	result, err := tx.ExecContext(ctx, query, oldID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+
+	if err := insertAPIKey(ctx, tx, next); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (repo *PostgresAPIKeyStore) TouchAPIKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	query := `UPDATE api_keys SET last_used_at=$1 WHERE id=$2`
+	if _, err := repo.db.ExecContext(ctx, query, usedAt, id); err != nil {
+		return fmt.Errorf("failed to update api key last use: %w", err)
+	}
+	return nil
+}
+
+func insertAPIKey(ctx context.Context, db execer, key domain.APIKey) error {
+	query := `INSERT INTO api_keys (id, user_id, name, prefix, key_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := db.ExecContext(ctx, query,
+		key.Id,
+		key.UserID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		pq.Array(permissionStrings(key.Scopes)),
+		key.ExpiresAt,
+		key.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	var (
+		key        domain.APIKey
+		scopes     pq.StringArray
+		expiresAt  sql.NullTime
+		lastUsedAt sql.NullTime
+		revokedAt  sql.NullTime
+	)
+	if err := row.Scan(
+		&key.Id,
+		&key.UserID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&scopes,
+		&expiresAt,
+		&lastUsedAt,
+		&revokedAt,
+		&key.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to scan api key: %w", err)
+	}
+
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, domain.Permission(scope))
+	}
+	key.ExpiresAt = nullTimePtr(expiresAt)
+	key.LastUsedAt = nullTimePtr(lastUsedAt)
+	key.RevokedAt = nullTimePtr(revokedAt)
+	return &key, nil
+}
+
+func permissionStrings(perms []domain.Permission) []string {
+	result := make([]string, len(perms))
+	for i, perm := range perms {
+		result[i] = string(perm)
+	}
+	return result
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/ports"
+)
+
+type PostgresIdempotencyStore struct {
+	db *sql.DB
+}
+
+func NewPostgresIdempotencyStore(db *sql.DB) ports.IdempotencyRepository {
+	return &PostgresIdempotencyStore{db: db}
+}
+
+func (repo *PostgresIdempotencyStore) ReserveIdempotencyKey(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error) {
+	// An expired record is taken over as if the key were free.
+	query := `INSERT INTO idempotency_keys (principal, key, request_hash, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (principal, key) DO UPDATE
+			SET request_hash=EXCLUDED.request_hash, status_code=NULL, content_type='', body=NULL,
+				created_at=EXCLUDED.created_at, expires_at=EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at <= EXCLUDED.created_at`
+	result, err := repo.db.ExecContext(ctx, query, record.Principal, record.Key, record.RequestHash, record.CreatedAt, record.ExpiresAt)
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 1 {
+		return record, true, nil
+	}
+
+	var (
+		existing   = domain.IdempotencyRecord{Principal: record.Principal, Key: record.Key}
+		statusCode sql.NullInt64
+	)
+	query = `SELECT request_hash, status_code, content_type, body, created_at, expires_at
+		FROM idempotency_keys WHERE principal=$1 AND key=$2`
+	err = repo.db.QueryRowContext(ctx, query, record.Principal, record.Key).Scan(
+		&existing.RequestHash, &statusCode, &existing.ContentType, &existing.Body, &existing.CreatedAt, &existing.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The record was released or pruned in between; try again.
+		return repo.ReserveIdempotencyKey(ctx, record)
+	}
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	existing.StatusCode = int(statusCode.Int64)
+	return existing, false, nil
+}
+
+func (repo *PostgresIdempotencyStore) CompleteIdempotencyKey(ctx context.Context, record domain.IdempotencyRecord) error {
+	query := `UPDATE idempotency_keys SET status_code=$3, content_type=$4, body=$5, expires_at=$6
+		WHERE principal=$1 AND key=$2 AND status_code IS NULL`
+	if _, err := repo.db.ExecContext(ctx, query, record.Principal, record.Key,
+		record.StatusCode, record.ContentType, record.Body, record.ExpiresAt); err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (repo *PostgresIdempotencyStore) ReleaseIdempotencyKey(ctx context.Context, principal, key string) error {
+	query := `DELETE FROM idempotency_keys WHERE principal=$1 AND key=$2 AND status_code IS NULL`
+	if _, err := repo.db.ExecContext(ctx, query, principal, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (repo *PostgresIdempotencyStore) PruneIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	result, err := repo.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune idempotency keys: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected, nil
+}
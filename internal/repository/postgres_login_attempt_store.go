@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/ports"
+)
+
+type PostgresLoginAttemptStore struct {
+	db *sql.DB
+}
+
+func NewPostgresLoginAttemptStore(db *sql.DB) ports.LoginAttemptRepository {
+	return &PostgresLoginAttemptStore{db: db}
+}
+
+func (repo *PostgresLoginAttemptStore) GetLoginThrottle(ctx context.Context, key string) (domain.LoginThrottle, error) {
+	query := `SELECT failures, last_failure_at, locked_until FROM login_attempts WHERE key=$1`
+	throttle, err := scanLoginThrottle(repo.db.QueryRowContext(ctx, query, key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.LoginThrottle{}, nil
+	}
+	return throttle, err
+}
+
+func (repo *PostgresLoginAttemptStore) RecordLoginFailure(ctx context.Context, key string, now time.Time, window time.Duration) (domain.LoginThrottle, error) {
+	// A single upsert keeps concurrent failures from losing counts.
+	query := `INSERT INTO login_attempts (key, failures, last_failure_at) VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failure_at < $3 THEN 1 ELSE login_attempts.failures + 1 END,
+			locked_until = CASE WHEN login_attempts.last_failure_at < $3 THEN NULL ELSE login_attempts.locked_until END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING failures, last_failure_at, locked_until`
+	return scanLoginThrottle(repo.db.QueryRowContext(ctx, query, key, now, now.Add(-window)))
+}
+
+func (repo *PostgresLoginAttemptStore) LockLogin(ctx context.Context, key string, until time.Time) error {
+	query := `UPDATE login_attempts SET locked_until=$1 WHERE key=$2`
+	if _, err := repo.db.ExecContext(ctx, query, until, key); err != nil {
+		return fmt.Errorf("failed to lock login: %w", err)
+	}
+	return nil
+}
+
+func (repo *PostgresLoginAttemptStore) ResetLoginFailures(ctx context.Context, key string) error {
+	query := `DELETE FROM login_attempts WHERE key=$1`
+	if _, err := repo.db.ExecContext(ctx, query, key); err != nil {
+		return fmt.Errorf("failed to reset login failures: %w", err)
+	}
+	return nil
+}
+
+func (repo *PostgresLoginAttemptStore) PruneLoginAttempts(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM login_attempts WHERE last_failure_at < $1 AND (locked_until IS NULL OR locked_until < $1)`
+	result, err := repo.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune login attempts: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+func scanLoginThrottle(row *sql.Row) (domain.LoginThrottle, error) {
+	var (
+		throttle    domain.LoginThrottle
+		lockedUntil sql.NullTime
+	)
+	if err := row.Scan(&throttle.Failures, &throttle.LastFailureAt, &lockedUntil); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.LoginThrottle{}, err
+		}
+		return domain.LoginThrottle{}, fmt.Errorf("failed to scan login attempts: %w", err)
+	}
+	if lockedUntil.Valid {
+		throttle.LockedUntil = lockedUntil.Time
+	}
+	return throttle, nil
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/ports"
+)
+
+type PostgresMFAStore struct {
+	db *sql.DB
+}
+
+func NewPostgresMFAStore(db *sql.DB) ports.MFARepository {
+	return &PostgresMFAStore{db: db}
+}
+
+func (repo *PostgresMFAStore) SaveTOTP(ctx context.Context, enrollment domain.TOTPEnrollment) error {
+	// A confirmed enrollment must be deleted before enrolling again, so the
+	// upsert only ever replaces a pending one.
+	query := `INSERT INTO user_totp (user_id, secret, created_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET secret=EXCLUDED.secret, created_at=EXCLUDED.created_at, last_used_step=0
+		WHERE user_totp.confirmed_at IS NULL`
+	if _, err := repo.db.ExecContext(ctx, query, enrollment.UserID, enrollment.Secret, enrollment.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save totp: %w", err)
+	}
+	return nil
+}
+
+func (repo *PostgresMFAStore) GetTOTP(ctx context.Context, userID uuid.UUID) (*domain.TOTPEnrollment, error) {
+	query := `SELECT user_id, secret, confirmed_at, last_used_step, created_at FROM user_totp WHERE user_id=$1`
+
+	var (
+		enrollment  domain.TOTPEnrollment
+		confirmedAt sql.NullTime
+	)
+	if err := repo.db.QueryRowContext(ctx, query, userID).Scan(
+		&enrollment.UserID,
+		&enrollment.Secret,
+		&confirmedAt,
+		&enrollment.LastUsedStep,
+		&enrollment.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTOTPNotFound
+		}
+		return nil, fmt.Errorf("failed to scan totp: %w", err)
+	}
+	if confirmedAt.Valid {
+		enrollment.ConfirmedAt = &confirmedAt.Time
+	}
+	return &enrollment, nil
+}
+
+func (repo *PostgresMFAStore) ConfirmTOTP(ctx context.Context, userID uuid.UUID, step int64, codeHashes []string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE user_totp SET confirmed_at=NOW(), last_used_step=$1 WHERE user_id=$2`
+	result, err := tx.ExecContext(ctx, query, step, userID)
+	if err != nil {
+		return fmt.Errorf("failed to confirm totp: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrTOTPNotFound
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (repo *PostgresMFAStore) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	query := `UPDATE user_totp SET last_used_step=$1 WHERE user_id=$2 AND last_used_step < $1`
+	result, err := repo.db.ExecContext(ctx, query, step, userID)
+	if err != nil {
+		return fmt.Errorf("failed to record totp step: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrTOTPStepUsed
+	}
+	return nil
+}
+
+func (repo *PostgresMFAStore) DeleteTOTP(ctx context.Context, userID uuid.UUID) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id=$1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id=$1`, userID); err != nil {
+		return fmt.Errorf("failed to delete totp: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (repo *PostgresMFAStore) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (repo *PostgresMFAStore) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	query := `UPDATE mfa_recovery_codes SET used_at=NOW() WHERE user_id=$1 AND code_hash=$2 AND used_at IS NULL`
+	result, err := repo.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrRecoveryCodeInvalid
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, db execer, userID uuid.UUID, codeHashes []string) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id=$1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	for _, hash := range codeHashes {
+		query := `INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`
+		if _, err := db.ExecContext(ctx, query, userID, hash); err != nil {
+			return fmt.Errorf("failed to create recovery code: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/lib/pq"
+)
+
+// personBatchChunk is the number of rows written per statement, keeping
+// the parameter count well under the Postgres limit of 65535.
+const personBatchChunk = 1000
+
+func (repo *PostgresUserRepo) CreatePersons(ctx context.Context, persons []domain.Person, atomic bool) ([]domain.BatchResult, error) {
+	results := make([]domain.BatchResult, len(persons))
+	err := repo.inBatchTx(ctx, results, atomic, func(tx *sql.Tx) error {
+		return forEachChunk(persons, func(start int, chunk []domain.Person) error {
+			var (
+				rows []string
+				args []any
+			)
+			for _, person := range chunk {
+				hobbiesJSON, err := json.Marshal(person.Hobbies)
+				if err != nil {
+					return fmt.Errorf("failed to marshal hobbies: %w", err)
+				}
+				args = append(args, person.Id, person.Name, person.Age, hobbiesJSON, person.CreatedAt, person.UpdatedAt, person.Version, searchTerms(person))
+				rows = append(rows, placeholders(len(args)-7, 8))
+			}
+
+			query := `INSERT INTO persons (id, name, age, hobbies, created_at, updated_at, version, search_terms)
+				VALUES ` + strings.Join(rows, ", ") + `
+				ON CONFLICT (id) DO NOTHING RETURNING id, version`
+			written, err := writtenVersions(ctx, tx, query, args...)
+			if err != nil {
+				return personWriteError(err, "failed to create persons")
+			}
+
+			for i, person := range chunk {
+				results[start+i].Person = person
+				if _, ok := written[person.Id]; !ok {
+					results[start+i].Err = domain.ErrPersonExists
+				}
+			}
+			return nil
+		})
+	})
+	return results, err
+}
+
+func (repo *PostgresUserRepo) UpdatePersons(ctx context.Context, persons []domain.Person, atomic bool) ([]domain.BatchResult, error) {
+	results := make([]domain.BatchResult, len(persons))
+	err := repo.inBatchTx(ctx, results, atomic, func(tx *sql.Tx) error {
+		return forEachChunk(persons, func(start int, chunk []domain.Person) error {
+			var (
+				rows []string
+				args []any
+			)
+			for _, person := range chunk {
+				hobbiesJSON, err := json.Marshal(person.Hobbies)
+				if err != nil {
+					return fmt.Errorf("failed to marshal hobbies: %w", err)
+				}
+				args = append(args, person.Id, person.Name, person.Age, hobbiesJSON, person.UpdatedAt, person.Version, searchTerms(person))
+				n := len(args) - 6
+				rows = append(rows, fmt.Sprintf("($%d::uuid, $%d::text, $%d::integer, $%d::jsonb, $%d::timestamptz, $%d::bigint, $%d::text[])",
+					n, n+1, n+2, n+3, n+4, n+5, n+6))
+			}
+
+			query := `UPDATE persons AS p
+				SET name=v.name, age=v.age, hobbies=v.hobbies, search_terms=v.search_terms, updated_at=v.updated_at, version=p.version+1
+				FROM (VALUES ` + strings.Join(rows, ", ") + `) AS v(id, name, age, hobbies, updated_at, version, search_terms)
+				WHERE p.id=v.id AND p.deleted_at IS NULL AND (v.version=0 OR p.version=v.version)
+				RETURNING p.id, p.version`
+			written, err := writtenVersions(ctx, tx, query, args...)
+			if err != nil {
+				return personWriteError(err, "failed to update persons")
+			}
+			return resolveBatchChunk(ctx, tx, results[start:], chunk, written)
+		})
+	})
+	return results, err
+}
+
+func (repo *PostgresUserRepo) DeletePersons(ctx context.Context, persons []domain.Person, at time.Time, atomic bool) ([]domain.BatchResult, error) {
+	results := make([]domain.BatchResult, len(persons))
+	err := repo.inBatchTx(ctx, results, atomic, func(tx *sql.Tx) error {
+		return forEachChunk(persons, func(start int, chunk []domain.Person) error {
+			rows := make([]string, 0, len(chunk))
+			args := []any{at}
+			for _, person := range chunk {
+				args = append(args, person.Id, person.Version)
+				rows = append(rows, fmt.Sprintf("($%d::uuid, $%d::bigint)", len(args)-1, len(args)))
+			}
+
+			query := `UPDATE persons AS p SET deleted_at=$1, version=p.version+1
+				FROM (VALUES ` + strings.Join(rows, ", ") + `) AS v(id, version)
+				WHERE p.id=v.id AND p.deleted_at IS NULL AND (v.version=0 OR p.version=v.version)
+				RETURNING p.id, p.version`
+			written, err := writtenVersions(ctx, tx, query, args...)
+			if err != nil {
+				return fmt.Errorf("failed to delete persons: %w", err)
+			}
+			return resolveBatchChunk(ctx, tx, results[start:], chunk, written)
+		})
+	})
+	return results, err
+}
+
+// inBatchTx runs write in a transaction, which it commits unless the batch
+// is atomic and one of its items failed.
+func (repo *PostgresUserRepo) inBatchTx(ctx context.Context, results []domain.BatchResult, atomic bool, write func(tx *sql.Tx) error) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := write(tx); err != nil {
+		return err
+	}
+	if abortBatch(results, atomic) {
+		return nil
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func forEachChunk(persons []domain.Person, fn func(start int, chunk []domain.Person) error) error {
+	for start := 0; start < len(persons); start += personBatchChunk {
+		if err := fn(start, persons[start:min(start+personBatchChunk, len(persons))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// placeholders renders one VALUES row of count parameters from $first.
+func placeholders(first, count int) string {
+	params := make([]string, count)
+	for i := range params {
+		params[i] = fmt.Sprintf("$%d", first+i)
+	}
+	return "(" + strings.Join(params, ", ") + ")"
+}
+
+// writtenVersions runs a batch statement returning id and version of every
+// row it wrote.
+func writtenVersions(ctx context.Context, tx *sql.Tx, query string, args ...any) (map[uuid.UUID]int64, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	written := make(map[uuid.UUID]int64)
+	for rows.Next() {
+		var (
+			id      uuid.UUID
+			version int64
+		)
+		if err := rows.Scan(&id, &version); err != nil {
+			return nil, err
+		}
+		written[id] = version
+	}
+	return written, rows.Err()
+}
+
+// resolveBatchChunk fills the results of a conditional batch write on
+// chunk. Persons that were not written are missing, deleted or at another
+// version, as missedPerson tells for single writes.
+func resolveBatchChunk(ctx context.Context, tx *sql.Tx, results []domain.BatchResult, chunk []domain.Person, written map[uuid.UUID]int64) error {
+	var missed []string
+	for i, person := range chunk {
+		results[i].Person = person
+		if version, ok := written[person.Id]; ok {
+			results[i].Person.Version = version
+		} else {
+			missed = append(missed, person.Id.String())
+		}
+	}
+	if len(missed) == 0 {
+		return nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM persons WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL`, pq.Array(missed))
+	if err != nil {
+		return fmt.Errorf("failed to look up persons: %w", err)
+	}
+	defer rows.Close()
+
+	live := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return fmt.Errorf("failed to look up persons: %w", err)
+		}
+		live[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to look up persons: %w", err)
+	}
+
+	for i, person := range chunk {
+		if _, ok := written[person.Id]; ok {
+			continue
+		}
+		if live[person.Id] {
+			results[i].Err = domain.ErrPersonVersionMismatch
+		} else {
+			results[i].Err = domain.ErrPersonNotFound
+		}
+	}
+	return nil
+}
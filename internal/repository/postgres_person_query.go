@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/lib/pq"
+)
+
+const personColumns = `id, name, age, hobbies, created_at, updated_at, version, deleted_at`
+
+// searchTerms is the search_terms column of person, from which the search
+// vector is generated.
+func searchTerms(person domain.Person) any {
+	return pq.Array(domain.PersonSearchTerms(person))
+}
+
+// personSortColumns maps sort fields to ORDER BY expressions. Names are
+// compared on their lowercase form in the "C" collation, i.e. byte by byte,
+// with the original spelling breaking ties, so the order does not depend on
+// the database locale.
+var personSortColumns = map[domain.PersonSortField][]string{
+	domain.SortByCreatedAt: {"created_at"},
+	domain.SortByUpdatedAt: {"updated_at"},
+	domain.SortByName:      {`lower(name) COLLATE "C"`, `name COLLATE "C"`},
+	domain.SortByAge:       {"age"},
+}
+
+// personFilterClause builds the WHERE clause for the filter and cursor of
+// query and its arguments, numbered from $1. Deleted persons are excluded
+// unless the filter asks for them alone.
+func personFilterClause(query domain.PersonQuery) (string, []any) {
+	filter := query.PersonFilter
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Deleted {
+		conds = append(conds, `deleted_at IS NOT NULL`)
+	} else {
+		conds = append(conds, `deleted_at IS NULL`)
+	}
+
+	if filter.NameContains != "" {
+		conds = append(conds, `lower(name) LIKE `+arg("%"+escapeLike(strings.ToLower(filter.NameContains))+"%"))
+	}
+	if filter.NamePrefix != "" {
+		conds = append(conds, `lower(name) LIKE `+arg(escapeLike(strings.ToLower(filter.NamePrefix))+"%"))
+	}
+	if filter.MinAge != nil {
+		conds = append(conds, `age >= `+arg(*filter.MinAge))
+	}
+	if filter.MaxAge != nil {
+		conds = append(conds, `age <= `+arg(*filter.MaxAge))
+	}
+	if len(filter.Hobbies) > 0 {
+		hobbiesJSON, _ := json.Marshal(filter.Hobbies)
+		conds = append(conds, `hobbies @> `+arg(string(hobbiesJSON))+`::jsonb`)
+	}
+	if terms := domain.SearchTerms(filter.Search); len(terms) > 0 {
+		// The query is cast rather than parsed by to_tsquery, so its terms
+		// match the stored search_terms as they are. Terms only hold letters
+		// and digits, so quoting them needs no escapes.
+		for i, term := range terms {
+			terms[i] = "'" + term + "':*"
+		}
+		conds = append(conds, `search @@ `+arg(strings.Join(terms, " & "))+`::tsquery`)
+	}
+
+	if query.Cursor != nil {
+		conds = append(conds, personKeysetCondition(*query.Cursor, arg))
+	}
+
+	return ` WHERE ` + strings.Join(conds, " AND "), args
+}
+
+// personOrderClause orders by field and then id, all in one direction.
+func personOrderClause(field domain.PersonSortField, desc bool) string {
+	dir := " ASC"
+	if desc {
+		dir = " DESC"
+	}
+
+	columns, ok := personSortColumns[field]
+	if !ok {
+		columns = personSortColumns[domain.SortByCreatedAt]
+	}
+	order := make([]string, 0, len(columns)+1)
+	for _, column := range append(columns, "id") {
+		order = append(order, column+dir)
+	}
+	return strings.Join(order, ", ")
+}
+
+// personKeysetCondition selects the rows after cursor in its sort order, or
+// before it when cursor.Before is set, by comparing the sort key as a row so
+// the sort index can be used.
+func personKeysetCondition(cursor domain.PersonCursor, arg func(any) string) string {
+	var columns, values string
+	switch cursor.SortBy {
+	case domain.SortByName:
+		name := arg(cursor.Name)
+		columns = `lower(name) COLLATE "C", name COLLATE "C", id`
+		values = `lower(` + name + `::text), ` + name + `::text, ` + arg(cursor.Id) + `::uuid`
+	case domain.SortByAge:
+		columns = `age, id`
+		values = arg(cursor.Age) + `::integer, ` + arg(cursor.Id) + `::uuid`
+	case domain.SortByUpdatedAt:
+		columns = `updated_at, id`
+		values = arg(cursor.UpdatedAt) + `::timestamptz, ` + arg(cursor.Id) + `::uuid`
+	default:
+		columns = `created_at, id`
+		values = arg(cursor.CreatedAt) + `::timestamptz, ` + arg(cursor.Id) + `::uuid`
+	}
+
+	op := ">"
+	if cursor.SortDesc != cursor.Before {
+		op = "<"
+	}
+	return `(` + columns + `) ` + op + ` (` + values + `)`
+}
+
+// escapeLike makes s match literally inside a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func scanPerson(row rowScanner) (*domain.Person, error) {
+	var (
+		person      domain.Person
+		hobbiesJSON []byte
+		deletedAt   sql.NullTime
+	)
+	if err := row.Scan(
+		&person.Id,
+		&person.Name,
+		&person.Age,
+		&hobbiesJSON,
+		&person.CreatedAt,
+		&person.UpdatedAt,
+		&person.Version,
+		&deletedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to scan person: %w", err)
+	}
+
+	if err := json.Unmarshal(hobbiesJSON, &person.Hobbies); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal hobbies: %w", err)
+	}
+	if deletedAt.Valid {
+		person.DeletedAt = &deletedAt.Time
+	}
+	return &person, nil
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/ports"
+)
+
+type PostgresRefreshTokenStore struct {
+	db *sql.DB
+}
+
+func NewPostgresRefreshTokenStore(db *sql.DB) ports.RefreshTokenRepository {
+	return &PostgresRefreshTokenStore{db: db}
+}
+
+func (repo *PostgresRefreshTokenStore) CreateRefreshToken(ctx context.Context, token domain.RefreshToken) error {
+	return insertRefreshToken(ctx, repo.db, token)
+}
+
+func (repo *PostgresRefreshTokenStore) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	query := `SELECT id, user_id, family_id, token_hash, expires_at, created_at, revoked_at, replaced_by, mfa
+		FROM refresh_tokens WHERE token_hash=$1`
+	row := repo.db.QueryRowContext(ctx, query, tokenHash)
+
+	var (
+		token      domain.RefreshToken
+		revokedAt  sql.NullTime
+		replacedBy uuid.NullUUID
+	)
+	if err := row.Scan(
+		&token.Id,
+		&token.UserID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&revokedAt,
+		&replacedBy,
+		&token.MFA,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRefreshTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to scan refresh token: %w", err)
+	}
+
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	if replacedBy.Valid {
+		token.ReplacedBy = &replacedBy.UUID
+	}
+	return &token, nil
+}
+
+func (repo *PostgresRefreshTokenStore) RotateRefreshToken(ctx context.Context, oldID uuid.UUID, next domain.RefreshToken) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Only an unrevoked token may be rotated; a concurrent rotation of the
+	// same token loses here and is treated as reuse.
+	query := `UPDATE refresh_tokens SET revoked_at=NOW(), replaced_by=$1 WHERE id=$2 AND revoked_at IS NULL`
+	result, err := tx.ExecContext(ctx, query, next.Id, oldID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return domain.ErrRefreshTokenRevoked
+	}
+
+	if err := insertRefreshToken(ctx, tx, next); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (repo *PostgresRefreshTokenStore) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked_at=NOW() WHERE family_id=$1 AND revoked_at IS NULL`
+	if _, err := repo.db.ExecContext(ctx, query, familyID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return nil
+}
+
+func (repo *PostgresRefreshTokenStore) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked_at=NOW() WHERE user_id=$1 AND revoked_at IS NULL`
+	if _, err := repo.db.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to revoke user refresh tokens: %w", err)
+	}
+	return nil
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func insertRefreshToken(ctx context.Context, db execer, token domain.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at, mfa)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := db.ExecContext(ctx, query,
+		token.Id,
+		token.UserID,
+		token.FamilyID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+		token.MFA,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	return nil
+}
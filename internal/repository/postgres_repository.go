@@ -6,14 +6,23 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
 	"github.com/izymalhaw/go-crud/yishakterefe/internal/ports"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 
+// Postgres error codes for values a column cannot hold.
+const (
+	stringTooLong     = "22001"
+	numericOutOfRange = "22003"
+)
+
 type PostgresUserRepo struct {
 	db *sql.DB
 }
@@ -28,23 +37,39 @@ func (repo *PostgresUserRepo) CreatePerson(ctx context.Context, person domain.Pe
 		return fmt.Errorf("failed to marshal hobbies: %w", err)
 	}
 
-	query := `INSERT INTO persons (id, name, age, hobbies) VALUES ($1, $2, $3, $4)`
+	query := `INSERT INTO persons (id, name, age, hobbies, created_at, updated_at, version, search_terms) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
 	_, err = repo.db.ExecContext(ctx, query,
 		person.Id,
 		person.Name,
 		person.Age,
 		hobbiesJSON,
+		person.CreatedAt,
+		person.UpdatedAt,
+		person.Version,
+		searchTerms(person),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create person: %w", err)
+		return personWriteError(err, "failed to create person")
 	}
 	return nil
 }
 
 
-func (repo *PostgresUserRepo) GetAllPersons(ctx context.Context, limit, offset int) ([]domain.Person, error) {
-	query := `SELECT id, name, age, hobbies FROM persons ORDER BY created_at DESC LIMIT $1 OFFSET $2`
-	rows, err := repo.db.QueryContext(ctx, query, limit, offset)
+func (repo *PostgresUserRepo) GetAllPersons(ctx context.Context, query domain.PersonQuery) ([]domain.Person, error) {
+	where, args := personFilterClause(query)
+
+	// A page before the cursor is read backwards from it and then flipped.
+	backward := query.Cursor != nil && query.Cursor.Before
+	offset := query.Offset
+	if query.Cursor != nil {
+		offset = 0
+	}
+	args = append(args, query.Limit, offset)
+	stmt := `SELECT ` + personColumns + ` FROM persons` + where +
+		` ORDER BY ` + personOrderClause(query.SortBy, query.SortDesc != backward) +
+		fmt.Sprintf(` LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+
+	rows, err := repo.db.QueryContext(ctx, stmt, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get persons: %w", err)
 	}
@@ -52,63 +77,142 @@ func (repo *PostgresUserRepo) GetAllPersons(ctx context.Context, limit, offset i
 
 	var persons []domain.Person
 	for rows.Next() {
-		var person domain.Person
-		var hobbiesJSON []byte
-
-		if err := rows.Scan(
-			&person.Id,
-			&person.Name,
-			&person.Age,
-			&hobbiesJSON,
-		); err != nil {
-			return nil, fmt.Errorf("failed to scan person: %w", err)
-		}
-
-		if err := json.Unmarshal(hobbiesJSON, &person.Hobbies); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal hobbies: %w", err)
+		person, err := scanPerson(rows)
+		if err != nil {
+			return nil, err
 		}
-
-		persons = append(persons, person)
+		persons = append(persons, *person)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows error: %w", err)
 	}
 
+	if backward {
+		slices.Reverse(persons)
+	}
 	return persons, nil
 }
 
-func (repo *PostgresUserRepo) UpdatePerson(ctx context.Context, person domain.Person) error {
+// CountPersons counts the persons matching filter using the same WHERE
+// clause, and so the same indexes, as GetAllPersons.
+func (repo *PostgresUserRepo) CountPersons(ctx context.Context, filter domain.PersonFilter) (int, error) {
+	where, args := personFilterClause(domain.PersonQuery{PersonFilter: filter})
+
+	var count int
+	if err := repo.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM persons`+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count persons: %w", err)
+	}
+	return count, nil
+}
+
+func (repo *PostgresUserRepo) UpdatePerson(ctx context.Context, person domain.Person) (*domain.Person, error) {
+	return repo.PatchPerson(ctx, person, []domain.PersonField{
+		domain.PersonFieldName,
+		domain.PersonFieldAge,
+		domain.PersonFieldHobbies,
+	})
+}
+
+// UpsertPerson relies on versions to tell the two outcomes apart: an
+// insert stores version 1, while an update leaves at least 2.
+func (repo *PostgresUserRepo) UpsertPerson(ctx context.Context, person domain.Person) (*domain.Person, bool, error) {
+	if person.Version != 0 {
+		updated, err := repo.UpdatePerson(ctx, person)
+		if errors.Is(err, domain.ErrPersonNotFound) {
+			// A deleted person is not found by the update, but its id is
+			// still taken.
+			var deleted bool
+			if err := repo.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM persons WHERE id=$1 AND deleted_at IS NOT NULL)`, person.Id).Scan(&deleted); err != nil {
+				return nil, false, fmt.Errorf("failed to look up person: %w", err)
+			}
+			if deleted {
+				return nil, false, domain.ErrPersonDeleted
+			}
+		}
+		return updated, false, err
+	}
+
 	hobbiesJSON, err := json.Marshal(person.Hobbies)
 	if err != nil {
-		return fmt.Errorf("failed to marshal hobbies: %w", err)
+		return nil, false, fmt.Errorf("failed to marshal hobbies: %w", err)
 	}
 
-	query := `UPDATE persons SET name=$1, age=$2, hobbies=$3, updated_at=NOW() WHERE id=$4`
-	result, err := repo.db.ExecContext(ctx, query,
+	query := `INSERT INTO persons (id, name, age, hobbies, created_at, updated_at, version, search_terms) VALUES ($1, $2, $3, $4, $5, $6, 1, $7)
+		ON CONFLICT (id) DO UPDATE
+			SET name=EXCLUDED.name, age=EXCLUDED.age, hobbies=EXCLUDED.hobbies,
+				search_terms=EXCLUDED.search_terms, updated_at=EXCLUDED.updated_at, version=persons.version+1
+			WHERE persons.deleted_at IS NULL
+		RETURNING ` + personColumns
+	stored, err := scanPerson(repo.db.QueryRowContext(ctx, query,
+		person.Id,
 		person.Name,
 		person.Age,
 		hobbiesJSON,
-		person.Id,
-	)
+		person.CreatedAt,
+		person.UpdatedAt,
+		searchTerms(person),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		// The conflicting row was left alone, so it is deleted.
+		return nil, false, domain.ErrPersonDeleted
+	}
 	if err != nil {
-		return fmt.Errorf("failed to update person: %w", err)
+		return nil, false, personWriteError(err, "failed to upsert person")
 	}
+	return stored, stored.Version == 1, nil
+}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+func (repo *PostgresUserRepo) PatchPerson(ctx context.Context, person domain.Person, fields []domain.PersonField) (*domain.Person, error) {
+	var (
+		sets []string
+		args []any
+	)
+	set := func(column string, value any) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s=$%d", column, len(args)))
 	}
 
-	if rowsAffected == 0 {
-		return errors.New("user not found")
+	reindex := false
+	for _, field := range fields {
+		switch field {
+		case domain.PersonFieldName:
+			set("name", person.Name)
+			reindex = true
+		case domain.PersonFieldAge:
+			set("age", person.Age)
+		case domain.PersonFieldHobbies:
+			hobbiesJSON, err := json.Marshal(person.Hobbies)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal hobbies: %w", err)
+			}
+			set("hobbies", hobbiesJSON)
+			reindex = true
+		}
 	}
-	return nil
+	if reindex {
+		set("search_terms", searchTerms(person))
+	}
+	set("updated_at", person.UpdatedAt)
+	args = append(args, person.Id, person.Version)
+
+	query := `UPDATE persons SET ` + strings.Join(sets, ", ") + `, version=version+1` +
+		fmt.Sprintf(` WHERE id=$%d AND deleted_at IS NULL AND ($%d::bigint = 0 OR version=$%d)`, len(args)-1, len(args), len(args)) +
+		` RETURNING ` + personColumns
+	updated, err := scanPerson(repo.db.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repo.missedPerson(ctx, person.Id)
+	}
+	if err != nil {
+		return nil, personWriteError(err, "failed to update person")
+	}
+	return updated, nil
 }
 
-func (repo *PostgresUserRepo) DeletePerson(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM persons WHERE id=$1`
-	result, err := repo.db.ExecContext(ctx, query, id)
+func (repo *PostgresUserRepo) DeletePerson(ctx context.Context, id uuid.UUID, version int64, at time.Time) error {
+	query := `UPDATE persons SET deleted_at=$3, version=version+1
+		WHERE id=$1 AND deleted_at IS NULL AND ($2::bigint = 0 OR version=$2)`
+	result, err := repo.db.ExecContext(ctx, query, id, version, at)
 	if err != nil {
 		return fmt.Errorf("failed to delete person: %w", err)
 	}
@@ -119,33 +223,73 @@ func (repo *PostgresUserRepo) DeletePerson(ctx context.Context, id uuid.UUID) er
 	}
 
 	if rowsAffected == 0 {
-		return errors.New("user not found")
+		return repo.missedPerson(ctx, id)
 	}
 	return nil
 }
 
-func (repo *PostgresUserRepo) GetPerson(ctx context.Context, userID uuid.UUID) (*domain.Person, error) {
-	query := `SELECT id, name, age, hobbies FROM persons WHERE id=$1`
-	row := repo.db.QueryRowContext(ctx, query, userID)
-
-	var person domain.Person
-	var hobbiesJSON []byte
-
-	if err := row.Scan(
-		&person.Id,
-		&person.Name,
-		&person.Age,
-		&hobbiesJSON,
-	); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("user not found")
+func (repo *PostgresUserRepo) RestorePerson(ctx context.Context, id uuid.UUID, at time.Time) (*domain.Person, error) {
+	query := `UPDATE persons SET deleted_at=NULL, updated_at=$2, version=version+1
+		WHERE id=$1 AND deleted_at IS NOT NULL RETURNING ` + personColumns
+	restored, err := scanPerson(repo.db.QueryRowContext(ctx, query, id, at))
+	if errors.Is(err, sql.ErrNoRows) {
+		var exists bool
+		if err := repo.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM persons WHERE id=$1)`, id).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to look up person: %w", err)
 		}
-		return nil, fmt.Errorf("failed to scan person: %w", err)
+		if exists {
+			return nil, domain.ErrPersonNotDeleted
+		}
+		return nil, domain.ErrPersonNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore person: %w", err)
 	}
+	return restored, nil
+}
 
-	if err := json.Unmarshal(hobbiesJSON, &person.Hobbies); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal hobbies: %w", err)
+func (repo *PostgresUserRepo) PurgePersons(ctx context.Context, before time.Time) (int64, error) {
+	result, err := repo.db.ExecContext(ctx, `DELETE FROM persons WHERE deleted_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge persons: %w", err)
 	}
+	return result.RowsAffected()
+}
+
+// missedPerson explains why a conditional write on id touched no row: the
+// person is gone or deleted, or its version moved on.
+func (repo *PostgresUserRepo) missedPerson(ctx context.Context, id uuid.UUID) error {
+	var exists bool
+	if err := repo.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM persons WHERE id=$1 AND deleted_at IS NULL)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to look up person: %w", err)
+	}
+	if exists {
+		return domain.ErrPersonVersionMismatch
+	}
+	return domain.ErrPersonNotFound
+}
 
-	return &person, nil
+func (repo *PostgresUserRepo) GetPerson(ctx context.Context, userID uuid.UUID) (*domain.Person, error) {
+	query := `SELECT ` + personColumns + ` FROM persons WHERE id=$1 AND deleted_at IS NULL`
+	person, err := scanPerson(repo.db.QueryRowContext(ctx, query, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrPersonNotFound
+	}
+	return person, err
+}
+
+// personWriteError wraps a failed person write in the domain error of its
+// cause: a taken id is a conflict and a value the column cannot hold a
+// validation failure. Other failures are wrapped under failed.
+func personWriteError(err error, failed string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case uniqueViolation:
+			return domain.ErrPersonExists
+		case stringTooLong, numericOutOfRange:
+			return fmt.Errorf("%w: %s", domain.ErrValidation, pqErr.Message)
+		}
+	}
+	return fmt.Errorf("%s: %w", failed, err)
 }
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/ports"
+)
+
+type PostgresRevocationStore struct {
+	db *sql.DB
+}
+
+func NewPostgresRevocationStore(db *sql.DB) ports.RevocationRepository {
+	return &PostgresRevocationStore{db: db}
+}
+
+func (repo *PostgresRevocationStore) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	query := `INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING`
+	if _, err := repo.db.ExecContext(ctx, query, jti, expiresAt); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+func (repo *PostgresRevocationStore) RevokeUserTokens(ctx context.Context, userID uuid.UUID, before, expiresAt time.Time) error {
+	query := `INSERT INTO user_token_revocations (user_id, revoked_before, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET revoked_before=EXCLUDED.revoked_before, expires_at=EXCLUDED.expires_at`
+	if _, err := repo.db.ExecContext(ctx, query, userID, before, expiresAt); err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+	return nil
+}
+
+func (repo *PostgresRevocationStore) IsRevoked(ctx context.Context, jti string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti=$1)
+		OR EXISTS (SELECT 1 FROM user_token_revocations WHERE user_id=$2 AND revoked_before > $3)`
+	var revoked bool
+	if err := repo.db.QueryRowContext(ctx, query, jti, userID, issuedAt).Scan(&revoked); err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	return revoked, nil
+}
+
+func (repo *PostgresRevocationStore) PruneRevocations(ctx context.Context, now time.Time) (int64, error) {
+	var pruned int64
+	for _, query := range []string{
+		`DELETE FROM revoked_tokens WHERE expires_at <= $1`,
+		`DELETE FROM user_token_revocations WHERE expires_at <= $1`,
+	} {
+		result, err := repo.db.ExecContext(ctx, query, now)
+		if err != nil {
+			return pruned, fmt.Errorf("failed to prune revocations: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return pruned, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		pruned += rowsAffected
+	}
+	return pruned, nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/ports"
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the Postgres error code for a unique constraint failure.
+const uniqueViolation = "23505"
+
+const userColumns = `id, email, password_hash, role, created_at, updated_at`
+
+type PostgresUserStore struct {
+	db *sql.DB
+}
+
+func NewPostgresUserStore(db *sql.DB) ports.UserRepository {
+	return &PostgresUserStore{db: db}
+}
+
+func (repo *PostgresUserStore) CreateUser(ctx context.Context, user domain.User) error {
+	query := `INSERT INTO users (` + userColumns + `) VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := repo.db.ExecContext(ctx, query,
+		user.Id,
+		user.Email,
+		user.PasswordHash,
+		user.Role,
+		user.CreatedAt,
+		user.UpdatedAt,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return domain.ErrEmailTaken
+		}
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+	return nil
+}
+
+func (repo *PostgresUserStore) GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id=$1`
+	return repo.scanUser(repo.db.QueryRowContext(ctx, query, id))
+}
+
+func (repo *PostgresUserStore) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE LOWER(email)=LOWER($1)`
+	return repo.scanUser(repo.db.QueryRowContext(ctx, query, email))
+}
+
+func (repo *PostgresUserStore) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	query := `UPDATE users SET password_hash=$1, updated_at=NOW() WHERE id=$2`
+	result, err := repo.db.ExecContext(ctx, query, passwordHash, id)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (repo *PostgresUserStore) UpdateRole(ctx context.Context, id uuid.UUID, role domain.Role) error {
+	query := `UPDATE users SET role=$1, updated_at=NOW() WHERE id=$2`
+	result, err := repo.db.ExecContext(ctx, query, role, id)
+	if err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (repo *PostgresUserStore) scanUser(row *sql.Row) (*domain.User, error) {
+	var user domain.User
+	if err := row.Scan(
+		&user.Id,
+		&user.Email,
+		&user.PasswordHash,
+		&user.Role,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to scan user: %w", err)
+	}
+	return &user, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/ports"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/repository/migrations"
+)
+
+// The tests of this package run every case against each adapter of a port,
+// so that the in-memory stores keep behaving like the Postgres ones. The
+// Postgres adapters are only tested when TEST_DATABASE_URL names a
+// database the tests may migrate and empty.
+
+// adapter is an implementation of a port under test.
+type adapter[T any] struct {
+	name string
+	// new returns an empty store.
+	new func(t *testing.T) T
+}
+
+// testDB opens TEST_DATABASE_URL with the schema migrated and empties
+// tables, or returns nil when the variable is unset.
+func testDB(t *testing.T, tables ...string) *sql.DB {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		return nil
+	}
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrations.NewMigrator(db, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range tables {
+		if _, err := db.Exec(`TRUNCATE ` + table); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+// withAdapters runs test once per adapter, skipping Postgres when no test
+// database is configured.
+func withAdapters[T any](t *testing.T, adapters []adapter[T], test func(t *testing.T, newStore func(t *testing.T) T)) {
+	for _, a := range adapters {
+		t.Run(a.name, func(t *testing.T) {
+			test(t, a.new)
+		})
+	}
+}
+
+var personRepositories = []adapter[ports.PersonRepository]{
+	{name: "memory", new: func(t *testing.T) ports.PersonRepository { return NewInMemoryUserRepo() }},
+	{name: "postgres", new: func(t *testing.T) ports.PersonRepository {
+		db := testDB(t, "persons")
+		if db == nil {
+			t.Skip("TEST_DATABASE_URL is not set")
+		}
+		return NewPostgresUserRepo(db)
+	}},
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
@@ -17,3 +18,94 @@ func NewInMemoryUserRepo() *InMemoryUserRepo {
 		persons: make(map[uuid.UUID]domain.Person),
 	}
 }
+
+type InMemoryUserStore struct {
+	mu    sync.RWMutex
+	users map[uuid.UUID]domain.User
+}
+
+func NewInMemoryUserStore() *InMemoryUserStore {
+	return &InMemoryUserStore{
+		users: make(map[uuid.UUID]domain.User),
+	}
+}
+
+type InMemoryRefreshTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[uuid.UUID]domain.RefreshToken
+}
+
+func NewInMemoryRefreshTokenStore() *InMemoryRefreshTokenStore {
+	return &InMemoryRefreshTokenStore{
+		tokens: make(map[uuid.UUID]domain.RefreshToken),
+	}
+}
+
+type InMemoryAPIKeyStore struct {
+	mu   sync.RWMutex
+	keys map[uuid.UUID]domain.APIKey
+}
+
+func NewInMemoryAPIKeyStore() *InMemoryAPIKeyStore {
+	return &InMemoryAPIKeyStore{
+		keys: make(map[uuid.UUID]domain.APIKey),
+	}
+}
+
+type userRevocation struct {
+	revokedBefore time.Time
+	expiresAt     time.Time
+}
+
+type InMemoryRevocationStore struct {
+	mu     sync.RWMutex
+	tokens map[string]time.Time
+	users  map[uuid.UUID]userRevocation
+}
+
+func NewInMemoryRevocationStore() *InMemoryRevocationStore {
+	return &InMemoryRevocationStore{
+		tokens: make(map[string]time.Time),
+		users:  make(map[uuid.UUID]userRevocation),
+	}
+}
+
+type InMemoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]domain.LoginThrottle
+}
+
+func NewInMemoryLoginAttemptStore() *InMemoryLoginAttemptStore {
+	return &InMemoryLoginAttemptStore{
+		attempts: make(map[string]domain.LoginThrottle),
+	}
+}
+
+type InMemoryMFAStore struct {
+	mu            sync.Mutex
+	totp          map[uuid.UUID]domain.TOTPEnrollment
+	recoveryCodes map[uuid.UUID]map[string]bool
+}
+
+func NewInMemoryMFAStore() *InMemoryMFAStore {
+	return &InMemoryMFAStore{
+		totp:          make(map[uuid.UUID]domain.TOTPEnrollment),
+		recoveryCodes: make(map[uuid.UUID]map[string]bool),
+	}
+}
+
+type idempotencyKey struct {
+	principal string
+	key       string
+}
+
+type InMemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[idempotencyKey]domain.IdempotencyRecord
+}
+
+func NewInMemoryIdempotencyStore() *InMemoryIdempotencyStore {
+	return &InMemoryIdempotencyStore{
+		records: make(map[idempotencyKey]domain.IdempotencyRecord),
+	}
+}
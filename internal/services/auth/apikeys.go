@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// apiKeyScheme starts every API key so that leaked keys are easy to spot
+// and to tell apart from JWTs.
+const apiKeyScheme = "gck"
+
+// apiKeyTouchInterval limits how often last-used timestamps are written.
+const apiKeyTouchInterval = time.Minute
+
+var (
+	ErrInvalidAPIKey = domain.NewError(domain.ErrUnauthorized, "invalid api key")
+	ErrInvalidScope  = domain.NewError(domain.ErrInvalidInput, "invalid api key scope")
+	ErrAPIKeyName    = domain.NewError(domain.ErrInvalidInput, "api key name is required")
+)
+
+// IsAPIKey reports whether credential looks like an API key rather than a
+// JWT.
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, apiKeyScheme+"_")
+}
+
+// CreateAPIKey issues a key for userID limited to scopes, which must be a
+// subset of the user's own permissions. The plaintext key is only returned
+// here and cannot be recovered later.
+func (s *Service) CreateAPIKey(ctx context.Context, userID uuid.UUID, name string, scopes []domain.Permission, expiresAt *time.Time) (domain.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return domain.APIKey{}, "", ErrAPIKeyName
+	}
+
+	if len(scopes) == 0 {
+		return domain.APIKey{}, "", ErrInvalidScope
+	}
+
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(user.Role.Permissions(), scope) {
+			return domain.APIKey{}, "", ErrInvalidScope
+		}
+	}
+
+	key, raw, err := newAPIKey(userID, name, scopes, expiresAt)
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+	if err := s.apiKeys.CreateAPIKey(ctx, key); err != nil {
+		return domain.APIKey{}, "", err
+	}
+	return key, raw, nil
+}
+
+// ListAPIKeys returns the keys owned by userID.
+func (s *Service) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error) {
+	return s.apiKeys.ListAPIKeys(ctx, userID)
+}
+
+// RevokeAPIKey revokes one of userID's keys.
+func (s *Service) RevokeAPIKey(ctx context.Context, userID, keyID uuid.UUID) error {
+	if _, err := s.ownedAPIKey(ctx, userID, keyID); err != nil {
+		return err
+	}
+	return s.apiKeys.RevokeAPIKey(ctx, keyID)
+}
+
+// RotateAPIKey replaces one of userID's keys with a new secret carrying the
+// same name, scopes and expiry, revoking the old one.
+func (s *Service) RotateAPIKey(ctx context.Context, userID, keyID uuid.UUID) (domain.APIKey, string, error) {
+	old, err := s.ownedAPIKey(ctx, userID, keyID)
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+	if !old.Active(time.Now()) {
+		return domain.APIKey{}, "", domain.ErrAPIKeyNotFound
+	}
+
+	key, raw, err := newAPIKey(userID, old.Name, old.Scopes, old.ExpiresAt)
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+	if err := s.apiKeys.RotateAPIKey(ctx, old.Id, key); err != nil {
+		return domain.APIKey{}, "", err
+	}
+	return key, raw, nil
+}
+
+// AuthenticateAPIKey resolves a presented API key to its principal. The
+// principal's permissions are the key's scopes still granted by the owner's
+// current role.
+func (s *Service) AuthenticateAPIKey(ctx context.Context, rawKey string) (Principal, error) {
+	prefix, ok := apiKeyPrefix(rawKey)
+	if !ok {
+		return Principal{}, ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeys.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return Principal{}, ErrInvalidAPIKey
+		}
+		return Principal{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(rawKey)), []byte(key.KeyHash)) != 1 {
+		return Principal{}, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if !key.Active(now) {
+		return Principal{}, ErrInvalidAPIKey
+	}
+
+	user, err := s.users.GetUserByID(ctx, key.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return Principal{}, ErrInvalidAPIKey
+		}
+		return Principal{}, err
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		if err := s.apiKeys.TouchAPIKey(ctx, key.Id, now); err != nil {
+			return Principal{}, err
+		}
+	}
+
+	var perms []domain.Permission
+	for _, scope := range key.Scopes {
+		if slices.Contains(user.Role.Permissions(), scope) {
+			perms = append(perms, scope)
+		}
+	}
+	return Principal{
+		Subject:     user.Id.String(),
+		Role:        user.Role,
+		Permissions: perms,
+		APIKeyID:    key.Id.String(),
+	}, nil
+}
+
+func (s *Service) ownedAPIKey(ctx context.Context, userID, keyID uuid.UUID) (*domain.APIKey, error) {
+	key, err := s.apiKeys.GetAPIKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	// Report other users' keys as missing rather than forbidden so that
+	// key IDs cannot be probed.
+	if key.UserID != userID {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+// newAPIKey generates a key of the form gck_<prefix>_<secret>.
+func newAPIKey(userID uuid.UUID, name string, scopes []domain.Permission, expiresAt *time.Time) (domain.APIKey, string, error) {
+	prefixBytes := make([]byte, 6)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return domain.APIKey{}, "", err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return domain.APIKey{}, "", err
+	}
+
+	prefix := hex.EncodeToString(prefixBytes)
+	raw := apiKeyScheme + "_" + prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+
+	return domain.APIKey{
+		Id:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hashToken(raw),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now().UTC(),
+	}, raw, nil
+}
+
+func apiKeyPrefix(rawKey string) (string, bool) {
+	rest, ok := strings.CutPrefix(rawKey, apiKeyScheme+"_")
+	if !ok {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || prefix == "" || secret == "" {
+		return "", false
+	}
+	return prefix, true
+}
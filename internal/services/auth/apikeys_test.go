@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+func TestAPIKeyPrefix(t *testing.T) {
+	tests := []struct {
+		key        string
+		wantPrefix string
+		wantOK     bool
+	}{
+		{key: "gck_0a1b2c_secret", wantPrefix: "0a1b2c", wantOK: true},
+		{key: "gck_0a1b2c_sec_ret", wantPrefix: "0a1b2c", wantOK: true},
+		{key: "gck_0a1b2c_"},
+		{key: "gck__secret"},
+		{key: "gck_0a1b2c"},
+		{key: "xyz_0a1b2c_secret"},
+		{key: "eyJhbGciOiJSUzI1NiJ9.e30.sig"},
+		{key: ""},
+	}
+	for _, tt := range tests {
+		prefix, ok := apiKeyPrefix(tt.key)
+		if prefix != tt.wantPrefix || ok != tt.wantOK {
+			t.Errorf("apiKeyPrefix(%q) = %q, %v; want %q, %v", tt.key, prefix, ok, tt.wantPrefix, tt.wantOK)
+		}
+	}
+}
+
+func TestCreateAPIKeyScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    domain.Role
+		scopes  []domain.Permission
+		wantErr error
+	}{
+		{name: "within the role", role: domain.RoleEditor, scopes: []domain.Permission{domain.PermPersonRead, domain.PermPersonWrite}},
+		{name: "beyond the role", role: domain.RoleViewer, scopes: []domain.Permission{domain.PermPersonWrite}, wantErr: ErrInvalidScope},
+		{name: "unknown scope", role: domain.RoleAdmin, scopes: []domain.Permission{"person:fly"}, wantErr: ErrInvalidScope},
+		{name: "no scopes", role: domain.RoleAdmin, wantErr: ErrInvalidScope},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, testLockout)
+			user := newTestUser(t, s, "al@example.com", tt.role)
+			_, raw, err := s.CreateAPIKey(context.Background(), user.Id, "ci", tt.scopes, nil)
+			if !errors.Is(err, tt.wantErr) || tt.wantErr == nil && err != nil {
+				t.Fatalf("CreateAPIKey error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !IsAPIKey(raw) {
+				t.Errorf("issued key %q does not look like an API key", raw)
+			}
+		})
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	scopes := []domain.Permission{domain.PermPersonRead, domain.PermPersonWrite, domain.PermPersonRestore}
+	tests := []struct {
+		name string
+		// present returns the key to present, given the key issued to an
+		// admin with scopes.
+		present   func(t *testing.T, s *Service, user domain.User, key domain.APIKey, raw string) string
+		wantErr   error
+		wantPerms []domain.Permission
+	}{
+		{
+			name:      "issued key",
+			present:   func(t *testing.T, s *Service, user domain.User, key domain.APIKey, raw string) string { return raw },
+			wantPerms: scopes,
+		},
+		{
+			name: "wrong secret",
+			present: func(t *testing.T, s *Service, user domain.User, key domain.APIKey, raw string) string {
+				return raw[:strings.LastIndex(raw, "_")+1] + "guess"
+			},
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name: "unknown prefix",
+			present: func(t *testing.T, s *Service, user domain.User, key domain.APIKey, raw string) string {
+				return "gck_000000_" + raw[strings.LastIndex(raw, "_")+1:]
+			},
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name: "revoked",
+			present: func(t *testing.T, s *Service, user domain.User, key domain.APIKey, raw string) string {
+				if err := s.RevokeAPIKey(context.Background(), user.Id, key.Id); err != nil {
+					t.Fatal(err)
+				}
+				return raw
+			},
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name: "rotated",
+			present: func(t *testing.T, s *Service, user domain.User, key domain.APIKey, raw string) string {
+				if _, _, err := s.RotateAPIKey(context.Background(), user.Id, key.Id); err != nil {
+					t.Fatal(err)
+				}
+				return raw
+			},
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name: "expired",
+			present: func(t *testing.T, s *Service, user domain.User, key domain.APIKey, raw string) string {
+				expiresAt := time.Now().Add(-time.Minute)
+				_, expired, err := s.CreateAPIKey(context.Background(), user.Id, "old", scopes, &expiresAt)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return expired
+			},
+			wantErr: ErrInvalidAPIKey,
+		},
+		{
+			name: "owner demoted",
+			present: func(t *testing.T, s *Service, user domain.User, key domain.APIKey, raw string) string {
+				if err := s.SetRole(context.Background(), user.Id, domain.RoleEditor); err != nil {
+					t.Fatal(err)
+				}
+				return raw
+			},
+			// Restoring is no longer granted by the role.
+			wantPerms: []domain.Permission{domain.PermPersonRead, domain.PermPersonWrite},
+		},
+		{
+			name: "owner demoted to viewer",
+			present: func(t *testing.T, s *Service, user domain.User, key domain.APIKey, raw string) string {
+				if err := s.SetRole(context.Background(), user.Id, domain.RoleViewer); err != nil {
+					t.Fatal(err)
+				}
+				return raw
+			},
+			wantPerms: []domain.Permission{domain.PermPersonRead},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestService(t, testLockout)
+			user := newTestUser(t, s, "al@example.com", domain.RoleAdmin)
+			key, raw, err := s.CreateAPIKey(ctx, user.Id, "ci", scopes, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			principal, err := s.AuthenticateAPIKey(ctx, tt.present(t, s, user, key, raw))
+			if !errors.Is(err, tt.wantErr) || tt.wantErr == nil && err != nil {
+				t.Fatalf("AuthenticateAPIKey error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if principal.Subject != user.Id.String() || principal.APIKeyID != key.Id.String() {
+				t.Errorf("principal = %+v, want user %s and key %s", principal, user.Id, key.Id)
+			}
+			if !reflect.DeepEqual(principal.Permissions, tt.wantPerms) {
+				t.Errorf("permissions = %v, want %v", principal.Permissions, tt.wantPerms)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+var (
+	ErrNoSigningKey       = errors.New("no active signing key")
+	ErrUnsupportedKeyType = errors.New("unsupported key type, use RSA or Ed25519")
+)
+
+// SigningKey is one entry of a KeySet. Keys without a private half are
+// retired from signing but still verify tokens issued before rotation.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	private   crypto.Signer
+	public    crypto.PublicKey
+}
+
+// CanSign reports whether the key has its private half.
+func (k *SigningKey) CanSign() bool {
+	return k.private != nil
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	if k.Algorithm == AlgEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// KeySet holds the keys used to sign and verify access tokens, identified
+// by their kid.
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// LoadKeySet reads every <kid>.pem file in dir. Files holding a PKCS#8
+// private key can sign; files holding only a PKIX public key are kept for
+// verification. activeKID selects the signing key and defaults to the only
+// private key when there is exactly one.
+func LoadKeySet(dir, activeKID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	ks := &KeySet{keys: make(map[string]*SigningKey)}
+	var signers []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+		key, err := parseKey(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		ks.keys[kid] = key
+		if key.CanSign() {
+			signers = append(signers, kid)
+		}
+	}
+
+	if activeKID == "" && len(signers) == 1 {
+		activeKID = signers[0]
+	}
+	active, ok := ks.keys[activeKID]
+	if !ok || !active.CanSign() {
+		return nil, fmt.Errorf("%w: %q in %s", ErrNoSigningKey, activeKID, dir)
+	}
+	ks.active = active
+	return ks, nil
+}
+
+// GenerateKeySet returns a key set with a single freshly generated Ed25519
+// key. Tokens signed with it do not survive a restart, so it is only meant
+// for development.
+func GenerateKeySet() (*KeySet, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{
+		ID:        "ephemeral",
+		Algorithm: AlgEdDSA,
+		private:   private,
+		public:    private.Public(),
+	}
+	return &KeySet{
+		active: key,
+		keys:   map[string]*SigningKey{key.ID: key},
+	}, nil
+}
+
+// Active returns the key new tokens are signed with.
+func (ks *KeySet) Active() *SigningKey {
+	return ks.active
+}
+
+// sign signs claims with the active key and records its kid in the header.
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	if ks.active == nil {
+		return "", ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(ks.active.method(), claims)
+	token.Header["kid"] = ks.active.ID
+	return token.SignedString(ks.active.private)
+}
+
+// keyFunc resolves the verification key by kid and pins the algorithm to
+// the one the key was registered with, so a token cannot pick its own.
+func (ks *KeySet) keyFunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	if t.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method %q for kid %q", t.Method.Alg(), kid)
+	}
+	return key.public, nil
+}
+
+// JWK is the public half of a signing key in RFC 7517 form.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of every key in the set, sorted by kid.
+func (ks *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(ks.keys))}
+	for _, key := range ks.keys {
+		jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Algorithm}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+	return set
+}
+
+// GenerateKeyPEM creates a new private key for alg encoded as PKCS#8 PEM,
+// suitable for dropping into the keys directory.
+func GenerateKeyPEM(alg string) ([]byte, error) {
+	var private any
+	switch alg {
+	case AlgRS256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	case AlgEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedKeyType, alg)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// PublicKeyPEM returns the PKIX public key of a PKCS#8 private key PEM, used
+// to retire a key from signing while keeping it for verification.
+func PublicKeyPEM(privatePEM []byte) ([]byte, error) {
+	key, err := parseKey("", privatePEM)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key.public)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+func parseKey(kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch private := parsed.(type) {
+		case *rsa.PrivateKey:
+			return &SigningKey{ID: kid, Algorithm: AlgRS256, private: private, public: private.Public()}, nil
+		case ed25519.PrivateKey:
+			return &SigningKey{ID: kid, Algorithm: AlgEdDSA, private: private, public: private.Public()}, nil
+		}
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch public := parsed.(type) {
+		case *rsa.PublicKey:
+			return &SigningKey{ID: kid, Algorithm: AlgRS256, public: public}, nil
+		case ed25519.PublicKey:
+			return &SigningKey{ID: kid, Algorithm: AlgEdDSA, public: public}, nil
+		}
+	default:
+		return nil, fmt.Errorf("unexpected PEM block %q", block.Type)
+	}
+	return nil, ErrUnsupportedKeyType
+}
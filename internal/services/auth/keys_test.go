@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// testKeys is a key set signing with an Ed25519 key, "ed", that also
+// verifies with an RSA key, "rsa", and a retired Ed25519 key, "retired".
+type testKeys struct {
+	set              *KeySet
+	ed, rsa, retired *SigningKey
+	retiredPrivate   ed25519.PrivateKey
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+	_, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, retiredPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := testKeys{
+		ed:             &SigningKey{ID: "ed", Algorithm: AlgEdDSA, private: edPrivate, public: edPrivate.Public()},
+		rsa:            &SigningKey{ID: "rsa", Algorithm: AlgRS256, private: rsaPrivate, public: rsaPrivate.Public()},
+		retired:        &SigningKey{ID: "retired", Algorithm: AlgEdDSA, public: retiredPrivate.Public()},
+		retiredPrivate: retiredPrivate,
+	}
+	keys.set = &KeySet{active: keys.ed, keys: map[string]*SigningKey{"ed": keys.ed, "rsa": keys.rsa, "retired": keys.retired}}
+	return keys
+}
+
+// signToken signs claims with method and private under kid, whatever key
+// kid names.
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, private interface{}, claims jwt.Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestValidateTokenKeys(t *testing.T) {
+	keys := newTestKeys(t)
+	_, strangerPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	claims := func(edit func(*Claims)) Claims {
+		c := Claims{RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   uuid.NewString(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		}}
+		if edit != nil {
+			edit(&c)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "active key", token: signToken(t, jwt.SigningMethodEdDSA, "ed", keys.ed.private, claims(nil))},
+		{name: "rsa key", token: signToken(t, jwt.SigningMethodRS256, "rsa", keys.rsa.private, claims(nil))},
+		{name: "retired key", token: signToken(t, jwt.SigningMethodEdDSA, "retired", keys.retiredPrivate, claims(nil))},
+		{name: "unknown kid", token: signToken(t, jwt.SigningMethodEdDSA, "stranger", strangerPrivate, claims(nil)), wantErr: ErrInvalidToken},
+		{name: "no kid", token: signToken(t, jwt.SigningMethodEdDSA, "", keys.ed.private, claims(nil)), wantErr: ErrInvalidToken},
+		{name: "foreign key under a known kid", token: signToken(t, jwt.SigningMethodEdDSA, "ed", strangerPrivate, claims(nil)), wantErr: ErrInvalidToken},
+		{name: "eddsa under the rsa kid", token: signToken(t, jwt.SigningMethodEdDSA, "rsa", keys.ed.private, claims(nil)), wantErr: ErrInvalidToken},
+		{name: "rs256 under the eddsa kid", token: signToken(t, jwt.SigningMethodRS256, "ed", keys.rsa.private, claims(nil)), wantErr: ErrInvalidToken},
+		{name: "hmac with the public key", token: signToken(t, jwt.SigningMethodHS256, "ed", []byte(keys.ed.public.(ed25519.PublicKey)), claims(nil)), wantErr: ErrInvalidToken},
+		{name: "no sub", token: signToken(t, jwt.SigningMethodEdDSA, "ed", keys.ed.private, claims(func(c *Claims) { c.Subject = "" })), wantErr: ErrInvalidToken},
+		{name: "no jti", token: signToken(t, jwt.SigningMethodEdDSA, "ed", keys.ed.private, claims(func(c *Claims) { c.ID = "" })), wantErr: ErrInvalidToken},
+		{name: "no exp", token: signToken(t, jwt.SigningMethodEdDSA, "ed", keys.ed.private, claims(func(c *Claims) { c.ExpiresAt = nil })), wantErr: ErrInvalidToken},
+		{name: "expired", token: signToken(t, jwt.SigningMethodEdDSA, "ed", keys.ed.private, claims(func(c *Claims) { c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute)) })), wantErr: ErrInvalidToken},
+		{name: "other use", token: signToken(t, jwt.SigningMethodEdDSA, "ed", keys.ed.private, claims(func(c *Claims) { c.Use = "mfa" })), wantErr: ErrInvalidToken},
+		{name: "malformed", token: "not.a.token", wantErr: ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, testLockout)
+			s.keys = keys.set
+			_, err := s.ValidateToken(context.Background(), tt.token)
+			if !errors.Is(err, tt.wantErr) || tt.wantErr == nil && err != nil {
+				t.Errorf("ValidateToken error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeyFunc(t *testing.T) {
+	keys := newTestKeys(t)
+	tests := []struct {
+		name   string
+		method jwt.SigningMethod
+		kid    interface{}
+		want   interface{}
+	}{
+		{name: "eddsa kid", method: jwt.SigningMethodEdDSA, kid: "ed", want: keys.ed.public},
+		{name: "rsa kid", method: jwt.SigningMethodRS256, kid: "rsa", want: keys.rsa.public},
+		{name: "unknown kid", method: jwt.SigningMethodEdDSA, kid: "other"},
+		{name: "kid not a string", method: jwt.SigningMethodEdDSA, kid: 1},
+		{name: "alg of another kid", method: jwt.SigningMethodRS256, kid: "ed"},
+		{name: "none", method: jwt.SigningMethodNone, kid: "ed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := &jwt.Token{Method: tt.method, Header: map[string]interface{}{"kid": tt.kid}}
+			got, err := keys.set.keyFunc(token)
+			if (err == nil) != (tt.want != nil) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keyFunc = %v, %v; want %v", got, err, tt.want)
+			}
+		})
+	}
+}
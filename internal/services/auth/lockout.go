@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyPasswordHash is compared against when the email is unknown, so that a
+// login for a missing account costs the same bcrypt work as a real one and
+// response times do not reveal which emails are registered.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password-for-timing"), bcrypt.DefaultCost)
+
+// LockoutPolicy bounds password guessing. Every failed login for an account
+// delays the next attempt by BaseDelay doubled per failure; once an account
+// reaches MaxAttempts failures, or a client IP reaches MaxIPAttempts, logins
+// from it are refused for Duration. Failures are forgotten after Duration
+// without a new one.
+type LockoutPolicy struct {
+	MaxAttempts   int
+	MaxIPAttempts int
+	BaseDelay     time.Duration
+	Duration      time.Duration
+}
+
+// LoginThrottledError is returned when a login is refused without checking
+// the password because of earlier failures.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, retry in %ds", e.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds is RetryAfter rounded up to whole seconds, as used by
+// the Retry-After header.
+func (e *LoginThrottledError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// Login authenticates email and password on behalf of clientIP, enforcing
+// the lockout policy. Failures are counted against both the account and the
+// IP; a success clears the account's failures only, so one valid account
+// cannot be used to reset an IP that is guessing at others.
+func (s *Service) Login(ctx context.Context, email, password, clientIP string) (domain.User, error) {
+	var user domain.User
+	err := s.throttlePasswordCheck(ctx, "login_failed", email, clientIP, func() (err error) {
+		user, err = s.Authenticate(ctx, email, password)
+		return err
+	})
+	if err != nil {
+		return domain.User{}, err
+	}
+	return user, nil
+}
+
+// throttlePasswordCheck runs check, a password check for the account with
+// email made by clientIP, under the lockout policy. A check failing with
+// ErrInvalidCredentials is audited as event and counted as a failed login.
+func (s *Service) throttlePasswordCheck(ctx context.Context, event, email, clientIP string, check func() error) error {
+	accountKey := "account:" + normalizeLoginEmail(email)
+	ipKey := "ip:" + clientIP
+
+	now := time.Now()
+	if err := s.checkThrottle(ctx, accountKey, true, now); err != nil {
+		return err
+	}
+	if err := s.checkThrottle(ctx, ipKey, false, now); err != nil {
+		return err
+	}
+
+	err := check()
+	if err == nil {
+		return s.loginAttempts.ResetLoginFailures(ctx, accountKey)
+	}
+	if !errors.Is(err, ErrInvalidCredentials) {
+		return err
+	}
+
+	s.logger.Info("login failed", "audit", true, "event", event, "email", email, "client_ip", clientIP)
+	if err := s.recordFailure(ctx, accountKey, s.lockout.MaxAttempts, now); err != nil {
+		return err
+	}
+	if err := s.recordFailure(ctx, ipKey, s.lockout.MaxIPAttempts, now); err != nil {
+		return err
+	}
+	return ErrInvalidCredentials
+}
+
+// checkThrottle refuses the login if key is locked out or, when backoff is
+// set, still inside the delay that follows its last failure.
+func (s *Service) checkThrottle(ctx context.Context, key string, backoff bool, now time.Time) error {
+	throttle, err := s.loginAttempts.GetLoginThrottle(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	retryAt := throttle.LockedUntil
+	if backoff && throttle.Failures > 0 {
+		if next := throttle.LastFailureAt.Add(s.backoffDelay(throttle.Failures)); next.After(retryAt) {
+			retryAt = next
+		}
+	}
+	if retryAt.After(now) {
+		return &LoginThrottledError{RetryAfter: retryAt.Sub(now)}
+	}
+	return nil
+}
+
+// recordFailure counts a failure against key and locks it once it reaches
+// limit. A limit of zero disables the lockout for that key.
+func (s *Service) recordFailure(ctx context.Context, key string, limit int, now time.Time) error {
+	throttle, err := s.loginAttempts.RecordLoginFailure(ctx, key, now, s.lockout.Duration)
+	if err != nil {
+		return err
+	}
+	if limit <= 0 || throttle.Failures < limit || throttle.LockedUntil.After(now) {
+		return nil
+	}
+
+	until := now.Add(s.lockout.Duration)
+	if err := s.loginAttempts.LockLogin(ctx, key, until); err != nil {
+		return err
+	}
+	s.logger.Warn("login locked out",
+		"audit", true,
+		"event", "login_lockout",
+		"key", key,
+		"failures", throttle.Failures,
+		"locked_until", until,
+	)
+	return nil
+}
+
+// backoffDelay is BaseDelay doubled for every failure after the first,
+// capped at the lockout duration.
+func (s *Service) backoffDelay(failures int) time.Duration {
+	delay := s.lockout.BaseDelay
+	for i := 1; i < failures && delay < s.lockout.Duration; i++ {
+		delay *= 2
+	}
+	return min(delay, s.lockout.Duration)
+}
+
+// PruneLoginAttempts drops failed-login entries that have been quiet for a
+// full lockout duration.
+func (s *Service) PruneLoginAttempts(ctx context.Context) (int64, error) {
+	return s.loginAttempts.PruneLoginAttempts(ctx, time.Now().Add(-s.lockout.Duration))
+}
+
+// normalizeLoginEmail keys attempts on the canonical address when it parses,
+// so case and whitespace variants share a counter.
+func normalizeLoginEmail(email string) string {
+	if normalized, err := normalizeEmail(email); err == nil {
+		return normalized
+	}
+	return email
+}
@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	s := &Service{lockout: LockoutPolicy{BaseDelay: time.Second, Duration: 10 * time.Second}}
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 1, want: time.Second},
+		{failures: 2, want: 2 * time.Second},
+		{failures: 3, want: 4 * time.Second},
+		{failures: 4, want: 8 * time.Second},
+		{failures: 5, want: 10 * time.Second},
+		{failures: 100, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := s.backoffDelay(tt.failures); got != tt.want {
+			t.Errorf("backoffDelay(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+// attempt is one login of a lockout test.
+type attempt struct {
+	email    string
+	password string
+	ip       string
+	// wantErr is nil, ErrInvalidCredentials or errThrottled.
+	wantErr error
+}
+
+// errThrottled stands for a LoginThrottledError in expectations.
+var errThrottled = errors.New("throttled")
+
+func TestLoginLockout(t *testing.T) {
+	wrong := func(email, ip string, wantErr error) attempt {
+		return attempt{email: email, password: "wrong password", ip: ip, wantErr: wantErr}
+	}
+	right := func(email, ip string, wantErr error) attempt {
+		return attempt{email: email, password: testPassword, ip: ip, wantErr: wantErr}
+	}
+	repeat := func(n int, a func(i int) attempt) []attempt {
+		attempts := make([]attempt, n)
+		for i := range attempts {
+			attempts[i] = a(i)
+		}
+		return attempts
+	}
+	concat := func(lists ...[]attempt) []attempt {
+		var all []attempt
+		for _, list := range lists {
+			all = append(all, list...)
+		}
+		return all
+	}
+
+	tests := []struct {
+		name     string
+		attempts []attempt
+	}{
+		{
+			name: "account locked after max attempts",
+			attempts: concat(
+				repeat(testLockout.MaxAttempts, func(int) attempt { return wrong("al@example.com", "10.0.0.1", ErrInvalidCredentials) }),
+				// Even the right password from another IP is refused.
+				[]attempt{right("al@example.com", "10.0.0.2", errThrottled)},
+				// Other accounts are unaffected.
+				[]attempt{right("bo@example.com", "10.0.0.1", nil)},
+			),
+		},
+		{
+			name: "account keyed on the normalized email",
+			attempts: concat(
+				repeat(testLockout.MaxAttempts, func(i int) attempt {
+					return wrong([]string{"AL@example.com", " al@EXAMPLE.com", "al@example.com"}[i%3], "10.0.0.1", ErrInvalidCredentials)
+				}),
+				[]attempt{right("al@example.com", "10.0.0.2", errThrottled)},
+			),
+		},
+		{
+			name: "success resets the account",
+			attempts: concat(
+				repeat(testLockout.MaxAttempts-1, func(int) attempt { return wrong("al@example.com", "10.0.0.1", ErrInvalidCredentials) }),
+				[]attempt{right("al@example.com", "10.0.0.1", nil)},
+				repeat(testLockout.MaxAttempts-1, func(int) attempt { return wrong("al@example.com", "10.0.0.1", ErrInvalidCredentials) }),
+				[]attempt{right("al@example.com", "10.0.0.1", nil)},
+			),
+		},
+		{
+			name: "ip locked across accounts",
+			attempts: concat(
+				repeat(testLockout.MaxIPAttempts, func(i int) attempt {
+					return wrong(fmt.Sprintf("user%d@example.com", i), "10.0.0.1", ErrInvalidCredentials)
+				}),
+				[]attempt{right("al@example.com", "10.0.0.1", errThrottled)},
+				[]attempt{right("al@example.com", "10.0.0.2", nil)},
+			),
+		},
+		{
+			name: "success does not reset the ip",
+			attempts: concat(
+				repeat(testLockout.MaxIPAttempts-1, func(i int) attempt {
+					return wrong(fmt.Sprintf("user%d@example.com", i), "10.0.0.1", ErrInvalidCredentials)
+				}),
+				[]attempt{right("al@example.com", "10.0.0.1", nil)},
+				[]attempt{wrong("user@example.com", "10.0.0.1", ErrInvalidCredentials)},
+				[]attempt{right("al@example.com", "10.0.0.1", errThrottled)},
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, testLockout)
+			newTestUser(t, s, "al@example.com", domain.RoleViewer)
+			newTestUser(t, s, "bo@example.com", domain.RoleViewer)
+			for i, a := range tt.attempts {
+				checkLogin(t, s, i, a)
+			}
+		})
+	}
+}
+
+func TestLoginBackoff(t *testing.T) {
+	const base = 10 * time.Second
+	tests := []struct {
+		name     string
+		failures int
+		// ago is how long ago the last failure was.
+		ago  time.Duration
+		want time.Duration
+	}{
+		{name: "one failure", failures: 1, want: base},
+		{name: "two failures", failures: 2, want: 2 * base},
+		{name: "three failures", failures: 3, want: 4 * base},
+		{name: "partly waited", failures: 3, ago: base, want: 3 * base},
+		{name: "waited out", failures: 3, ago: 4 * base},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestService(t, LockoutPolicy{MaxAttempts: 10, BaseDelay: base, Duration: time.Hour})
+			newTestUser(t, s, "al@example.com", domain.RoleViewer)
+			for i := 0; i < tt.failures; i++ {
+				if err := s.recordFailure(ctx, "account:al@example.com", 0, time.Now().Add(-tt.ago)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err := s.Login(ctx, "al@example.com", testPassword, "10.0.0.1")
+			var throttled *LoginThrottledError
+			if errors.As(err, &throttled) != (tt.want > 0) {
+				t.Fatalf("error = %v, want throttled for %v", err, tt.want)
+			}
+			if tt.want > 0 && (throttled.RetryAfter <= tt.want-time.Second || throttled.RetryAfter > tt.want) {
+				t.Errorf("retry after %v, want %v", throttled.RetryAfter, tt.want)
+			}
+		})
+	}
+}
+
+func checkLogin(t *testing.T, s *Service, i int, a attempt) {
+	t.Helper()
+	_, err := s.Login(context.Background(), a.email, a.password, a.ip)
+	var throttled *LoginThrottledError
+	switch {
+	case a.wantErr == errThrottled:
+		if !errors.As(err, &throttled) {
+			t.Fatalf("login %d (%s from %s): error = %v, want throttled", i, a.email, a.ip, err)
+		}
+	case !errors.Is(err, a.wantErr) || a.wantErr == nil && err != nil:
+		t.Fatalf("login %d (%s from %s): error = %v, want %v", i, a.email, a.ip, err, a.wantErr)
+	}
+}
@@ -0,0 +1,311 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+const (
+	// tokenUseMFA marks the short-lived token that proves the password step
+	// of a login succeeded. It is only accepted by CompleteMFALogin.
+	tokenUseMFA     = "mfa"
+	mfaChallengeTTL = 5 * time.Minute
+
+	recoveryCodeCount = 10
+	// amrOTP is the RFC 8176 authentication method recorded in access
+	// tokens issued after a second factor.
+	amrOTP = "otp"
+)
+
+var (
+	ErrMFAAlreadyEnabled = domain.NewError(domain.ErrConflict, "two-factor authentication is already enabled")
+	ErrMFANotEnabled     = domain.NewError(domain.ErrConflict, "two-factor authentication is not enabled")
+	ErrInvalidMFACode    = domain.NewError(domain.ErrUnauthorized, "invalid authentication code")
+	ErrInvalidMFAToken   = domain.NewError(domain.ErrUnauthorized, "invalid or expired mfa token")
+)
+
+// TOTPSetup is what a user needs to add their account to an authenticator
+// app.
+type TOTPSetup struct {
+	Secret string
+	URI    string
+}
+
+// EnrollTOTP starts TOTP enrollment for userID with a new secret. The
+// enrollment stays inactive until ConfirmTOTP sees a valid code.
+func (s *Service) EnrollTOTP(ctx context.Context, userID uuid.UUID) (TOTPSetup, error) {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return TOTPSetup{}, err
+	}
+
+	existing, err := s.mfa.GetTOTP(ctx, userID)
+	if err != nil && !errors.Is(err, domain.ErrTOTPNotFound) {
+		return TOTPSetup{}, err
+	}
+	if existing != nil && existing.ConfirmedAt != nil {
+		return TOTPSetup{}, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return TOTPSetup{}, err
+	}
+	if err := s.mfa.SaveTOTP(ctx, domain.TOTPEnrollment{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: time.Now().UTC(),
+	}); err != nil {
+		return TOTPSetup{}, err
+	}
+	return TOTPSetup{Secret: secret, URI: totpURI(s.mfaIssuer, user.Email, secret)}, nil
+}
+
+// ConfirmTOTP activates a pending enrollment once code verifies against it,
+// and returns the user's recovery codes. They are only shown here.
+func (s *Service) ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	enrollment, err := s.mfa.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrTOTPNotFound) {
+			return nil, ErrMFANotEnabled
+		}
+		return nil, err
+	}
+	if enrollment.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	key := mfaThrottleKey(userID)
+	now := time.Now()
+	if err := s.checkThrottle(ctx, key, true, now); err != nil {
+		return nil, err
+	}
+	step, ok := validateTOTP(enrollment.Secret, code, now)
+	if !ok {
+		if err := s.recordFailure(ctx, key, s.lockout.MaxAttempts, now); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfa.ConfirmTOTP(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+	if err := s.loginAttempts.ResetLoginFailures(ctx, key); err != nil {
+		return nil, err
+	}
+	s.logger.Info("mfa enabled", "audit", true, "event", "mfa_enabled", "user_id", userID)
+	return codes, nil
+}
+
+// DisableTOTP removes the second factor of userID. code must be a current
+// TOTP code or an unused recovery code.
+func (s *Service) DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	if err := s.verifySecondFactor(ctx, userID, code); err != nil {
+		return err
+	}
+	if err := s.mfa.DeleteTOTP(ctx, userID); err != nil {
+		return err
+	}
+	s.logger.Warn("mfa disabled", "audit", true, "event", "mfa_disabled", "user_id", userID)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of userID after
+// verifying code, invalidating the old ones.
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	if err := s.verifySecondFactor(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfa.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// MFAChallenge returns a challenge token when user has a second factor
+// enabled, and an empty string when the password alone is enough.
+func (s *Service) MFAChallenge(ctx context.Context, user domain.User) (string, error) {
+	enabled, err := s.mfaEnabled(ctx, user.Id)
+	if err != nil || !enabled {
+		return "", err
+	}
+
+	now := time.Now()
+	return s.keys.sign(Claims{
+		Use: tokenUseMFA,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   user.Id.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(mfaChallengeTTL)),
+		},
+	})
+}
+
+// MFAChallengeTTL returns how long a challenge token can be exchanged.
+func (s *Service) MFAChallengeTTL() time.Duration {
+	return mfaChallengeTTL
+}
+
+// CompleteMFALogin exchanges a challenge token and a TOTP or recovery code
+// for a session marked as second-factor authenticated.
+func (s *Service) CompleteMFALogin(ctx context.Context, mfaToken, code string) (TokenPair, error) {
+	claims, err := s.parseToken(mfaToken)
+	if err != nil || claims.Use != tokenUseMFA {
+		return TokenPair{}, ErrInvalidMFAToken
+	}
+	revoked, err := s.isRevoked(ctx, claims)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if revoked {
+		return TokenPair{}, ErrInvalidMFAToken
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return TokenPair{}, ErrInvalidMFAToken
+	}
+	if err := s.verifySecondFactor(ctx, userID, code); err != nil {
+		return TokenPair{}, err
+	}
+
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return TokenPair{}, ErrInvalidMFAToken
+		}
+		return TokenPair{}, err
+	}
+	return s.IssueTokens(ctx, *user, true)
+}
+
+// MFARequired reports whether p must complete a second factor before being
+// let at protected data: admins who signed in with a password alone, when
+// the service is configured to require it. API keys are exempt because
+// admins can only issue them from a second-factor session.
+func (s *Service) MFARequired(p Principal) bool {
+	return s.requireAdminMFA && p.Role == domain.RoleAdmin && !p.MFA && p.APIKeyID == ""
+}
+
+// verifySecondFactor checks code against the confirmed enrollment of
+// userID, consuming it so it cannot be used twice. Failures count towards
+// the lockout policy like failed passwords.
+func (s *Service) verifySecondFactor(ctx context.Context, userID uuid.UUID, code string) error {
+	enrollment, err := s.mfa.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrTOTPNotFound) {
+			return ErrMFANotEnabled
+		}
+		return err
+	}
+	if enrollment.ConfirmedAt == nil {
+		return ErrMFANotEnabled
+	}
+
+	key := mfaThrottleKey(userID)
+	now := time.Now()
+	if err := s.checkThrottle(ctx, key, true, now); err != nil {
+		return err
+	}
+
+	err = s.useSecondFactor(ctx, enrollment, strings.TrimSpace(code), now)
+	if errors.Is(err, ErrInvalidMFACode) {
+		s.logger.Info("mfa failed", "audit", true, "event", "mfa_failed", "user_id", userID)
+		if err := s.recordFailure(ctx, key, s.lockout.MaxAttempts, now); err != nil {
+			return err
+		}
+		return ErrInvalidMFACode
+	}
+	if err != nil {
+		return err
+	}
+	return s.loginAttempts.ResetLoginFailures(ctx, key)
+}
+
+func (s *Service) useSecondFactor(ctx context.Context, enrollment *domain.TOTPEnrollment, code string, now time.Time) error {
+	if isTOTPCode(code) {
+		step, ok := validateTOTP(enrollment.Secret, code, now)
+		if !ok {
+			return ErrInvalidMFACode
+		}
+		if err := s.mfa.UseTOTPStep(ctx, enrollment.UserID, step); err != nil {
+			if errors.Is(err, domain.ErrTOTPStepUsed) {
+				return ErrInvalidMFACode
+			}
+			return err
+		}
+		return nil
+	}
+
+	err := s.mfa.UseRecoveryCode(ctx, enrollment.UserID, hashToken(normalizeRecoveryCode(code)))
+	if errors.Is(err, domain.ErrRecoveryCodeInvalid) {
+		return ErrInvalidMFACode
+	}
+	if err == nil {
+		s.logger.Warn("recovery code used", "audit", true, "event", "mfa_recovery_code_used", "user_id", enrollment.UserID)
+	}
+	return err
+}
+
+func (s *Service) mfaEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	enrollment, err := s.mfa.GetTOTP(ctx, userID)
+	if errors.Is(err, domain.ErrTOTPNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return enrollment.ConfirmedAt != nil, nil
+}
+
+func mfaThrottleKey(userID uuid.UUID) string {
+	return "mfa:" + userID.String()
+}
+
+// recoveryAlphabet leaves out characters that are easy to misread. Its 32
+// symbols map evenly onto random bytes.
+const recoveryAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+
+// newRecoveryCodes returns recovery codes of the form xxxxx-xxxxx along with
+// the hashes they are stored as.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		buf := make([]byte, 10)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		for j, b := range buf {
+			buf[j] = recoveryAlphabet[int(b)%len(recoveryAlphabet)]
+		}
+		codes[i] = string(buf[:5]) + "-" + string(buf[5:])
+		hashes[i] = hashToken(normalizeRecoveryCode(codes[i]))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode makes codes match regardless of case, spacing or
+// the dash.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"slices"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// Principal is the authenticated caller of a request, whether it presented
+// an access token or an API key.
+type Principal struct {
+	Subject     string
+	Role        domain.Role
+	Permissions []domain.Permission
+	// APIKeyID is set when the caller authenticated with an API key.
+	APIKeyID string
+	// MFA is set when the session was started with a second factor.
+	MFA bool
+}
+
+// HasPermission reports whether the principal was granted perm.
+func (p Principal) HasPermission(perm domain.Permission) bool {
+	return slices.Contains(p.Permissions, perm)
+}
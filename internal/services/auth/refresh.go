@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+var (
+	ErrInvalidRefreshToken = domain.NewError(domain.ErrUnauthorized, "invalid refresh token")
+	ErrRefreshTokenReused  = domain.NewError(domain.ErrUnauthorized, "refresh token reuse detected")
+)
+
+// TokenPair is the result of a successful login or refresh.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    time.Duration
+}
+
+// IssueTokens starts a new session for user with a fresh token family. mfa
+// records that the user passed a second factor, and carries over to every
+// token refreshed from the session.
+func (s *Service) IssueTokens(ctx context.Context, user domain.User, mfa bool) (TokenPair, error) {
+	return s.issue(ctx, user, uuid.New(), nil, mfa)
+}
+
+// Refresh exchanges a refresh token for a new token pair, rotating the
+// refresh token. Presenting a token that was already rotated or revoked is
+// treated as theft and revokes every token in its family.
+func (s *Service) Refresh(ctx context.Context, rawToken string) (TokenPair, error) {
+	current, err := s.refreshTokens.GetRefreshTokenByHash(ctx, hashToken(rawToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenNotFound) {
+			return TokenPair{}, ErrInvalidRefreshToken
+		}
+		return TokenPair{}, err
+	}
+
+	if current.RevokedAt != nil {
+		return TokenPair{}, s.revokeReusedFamily(ctx, current.FamilyID)
+	}
+	if time.Now().After(current.ExpiresAt) {
+		return TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	// Reload the user so the new access token reflects role changes.
+	user, err := s.users.GetUserByID(ctx, current.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return TokenPair{}, ErrInvalidRefreshToken
+		}
+		return TokenPair{}, err
+	}
+
+	pair, err := s.issue(ctx, *user, current.FamilyID, &current.Id, current.MFA)
+	if errors.Is(err, domain.ErrRefreshTokenRevoked) {
+		// Lost a race with another rotation of the same token.
+		return TokenPair{}, s.revokeReusedFamily(ctx, current.FamilyID)
+	}
+	return pair, err
+}
+
+// Logout revokes the session the refresh token belongs to. Unknown tokens
+// are ignored so that logging out twice is harmless.
+func (s *Service) Logout(ctx context.Context, rawToken string) error {
+	current, err := s.refreshTokens.GetRefreshTokenByHash(ctx, hashToken(rawToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenNotFound) {
+			return nil
+		}
+		return err
+	}
+	return s.refreshTokens.RevokeRefreshTokenFamily(ctx, current.FamilyID)
+}
+
+// issue signs an access token and stores a new refresh token in familyID,
+// rotating replaces when it is set.
+func (s *Service) issue(ctx context.Context, user domain.User, familyID uuid.UUID, replaces *uuid.UUID, mfa bool) (TokenPair, error) {
+	accessToken, err := s.GenerateToken(user, mfa)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	rawToken, err := newRefreshToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	now := time.Now().UTC()
+	record := domain.RefreshToken{
+		Id:        uuid.New(),
+		UserID:    user.Id,
+		FamilyID:  familyID,
+		TokenHash: hashToken(rawToken),
+		ExpiresAt: now.Add(s.refreshTokenTTL),
+		CreatedAt: now,
+		MFA:       mfa,
+	}
+
+	if replaces != nil {
+		err = s.refreshTokens.RotateRefreshToken(ctx, *replaces, record)
+	} else {
+		err = s.refreshTokens.CreateRefreshToken(ctx, record)
+	}
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: rawToken,
+		ExpiresIn:    s.accessTokenTTL,
+	}, nil
+}
+
+func (s *Service) revokeReusedFamily(ctx context.Context, familyID uuid.UUID) error {
+	if err := s.refreshTokens.RevokeRefreshTokenFamily(ctx, familyID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// newRefreshToken returns 32 random bytes encoded for transport.
+func newRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken is how refresh tokens are stored, so a database leak does not
+// expose usable tokens.
+func hashToken(rawToken string) string {
+	sum := sha256.Sum256([]byte(rawToken))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+func TestRefresh(t *testing.T) {
+	tests := []struct {
+		name string
+		// present returns the refresh token to present, given the session
+		// started for the test.
+		present func(t *testing.T, s *Service, session TokenPair) string
+		wantErr error
+	}{
+		{
+			name:    "current token",
+			present: func(t *testing.T, s *Service, session TokenPair) string { return session.RefreshToken },
+		},
+		{
+			name:    "unknown token",
+			present: func(t *testing.T, s *Service, session TokenPair) string { return "unknown" },
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "rotated token reused",
+			present: func(t *testing.T, s *Service, session TokenPair) string {
+				if _, err := s.Refresh(context.Background(), session.RefreshToken); err != nil {
+					t.Fatal(err)
+				}
+				return session.RefreshToken
+			},
+			wantErr: ErrRefreshTokenReused,
+		},
+		{
+			name: "logged out",
+			present: func(t *testing.T, s *Service, session TokenPair) string {
+				if err := s.Logout(context.Background(), session.RefreshToken); err != nil {
+					t.Fatal(err)
+				}
+				return session.RefreshToken
+			},
+			wantErr: ErrRefreshTokenReused,
+		},
+		{
+			name: "expired",
+			present: func(t *testing.T, s *Service, session TokenPair) string {
+				user, err := s.users.GetUserByEmail(context.Background(), "al@example.com")
+				if err != nil {
+					t.Fatal(err)
+				}
+				s.refreshTokenTTL = -time.Second
+				expired, err := s.IssueTokens(context.Background(), *user, false)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return expired.RefreshToken
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestService(t, testLockout)
+			user := newTestUser(t, s, "al@example.com", domain.RoleEditor)
+			session, err := s.IssueTokens(ctx, user, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pair, err := s.Refresh(ctx, tt.present(t, s, session))
+			if !errors.Is(err, tt.wantErr) || tt.wantErr == nil && err != nil {
+				t.Fatalf("Refresh error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			principal, err := s.ValidateToken(ctx, pair.AccessToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if principal.Subject != user.Id.String() || principal.Role != domain.RoleEditor || !principal.MFA {
+				t.Errorf("refreshed principal = %+v", principal)
+			}
+			if pair.RefreshToken == session.RefreshToken {
+				t.Error("refresh token was not rotated")
+			}
+		})
+	}
+
+	// Reusing a rotated token ends the whole session, including the token
+	// that replaced it.
+	t.Run("reuse revokes the family", func(t *testing.T) {
+		ctx := context.Background()
+		s := newTestService(t, testLockout)
+		user := newTestUser(t, s, "al@example.com", domain.RoleEditor)
+		first, err := s.IssueTokens(ctx, user, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := s.Refresh(ctx, first.RefreshToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		other, err := s.IssueTokens(ctx, user, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := s.Refresh(ctx, first.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+			t.Fatalf("reuse: error = %v, want ErrRefreshTokenReused", err)
+		}
+		if _, err := s.Refresh(ctx, second.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+			t.Errorf("successor after reuse: error = %v, want ErrRefreshTokenReused", err)
+		}
+		if _, err := s.Refresh(ctx, other.RefreshToken); err != nil {
+			t.Errorf("another session of the user: %v", err)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+var ErrRevocationTarget = domain.NewError(domain.ErrInvalidInput, "exactly one of jti or user_id is required")
+
+// RevokeToken revokes a single access token by its jti. The entry is kept
+// for one access token lifetime, which outlives any token carrying jti.
+func (s *Service) RevokeToken(ctx context.Context, jti string) error {
+	if jti == "" {
+		return ErrRevocationTarget
+	}
+	return s.revocations.RevokeToken(ctx, jti, time.Now().Add(s.accessTokenTTL))
+}
+
+// RevokeUser revokes every access token issued to userID so far and ends
+// all of their refresh token sessions.
+func (s *Service) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	if _, err := s.users.GetUserByID(ctx, userID); err != nil {
+		return err
+	}
+
+	// Tokens issued from now on, within the precision of their iat claim,
+	// stay valid.
+	now := time.Now().Truncate(jwt.TimePrecision)
+	if err := s.revocations.RevokeUserTokens(ctx, userID, now, now.Add(s.accessTokenTTL)); err != nil {
+		return err
+	}
+	return s.refreshTokens.RevokeUserRefreshTokens(ctx, userID)
+}
+
+// PruneRevocations drops revocation entries whose tokens have expired.
+func (s *Service) PruneRevocations(ctx context.Context) (int64, error) {
+	return s.revocations.PruneRevocations(ctx, time.Now())
+}
+
+// RunPruner calls PruneRevocations and PruneLoginAttempts every interval
+// until ctx is done.
+func (s *Service) RunPruner(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pruned, err := s.PruneRevocations(ctx)
+			if err != nil {
+				logger.Error("failed to prune token revocations", "error", err)
+				continue
+			}
+			if pruned > 0 {
+				logger.Info("pruned expired token revocations", "count", pruned)
+			}
+
+			pruned, err = s.PruneLoginAttempts(ctx)
+			if err != nil {
+				logger.Error("failed to prune login attempts", "error", err)
+				continue
+			}
+			if pruned > 0 {
+				logger.Info("pruned stale login attempts", "count", pruned)
+			}
+		}
+	}
+}
+
+// isRevoked checks a parsed access token against the revocation list.
+func (s *Service) isRevoked(ctx context.Context, claims Claims) (bool, error) {
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return true, nil
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	revoked, err := s.revocations.IsRevoked(ctx, claims.ID, userID, issuedAt)
+	if err != nil {
+		return false, err
+	}
+	return revoked, nil
+}
@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+func TestRevocation(t *testing.T) {
+	tests := []struct {
+		name string
+		// revoke revokes some tokens of al, who holds two sessions, while bo
+		// holds one.
+		revoke  func(s *Service, al, bo domain.User, sessions [3]TokenPair) error
+		wantErr error
+		// wantValid tells which access tokens of the sessions remain valid.
+		wantValid [3]bool
+		// wantRefresh is set when al's second session can still refresh.
+		wantRefresh bool
+	}{
+		{
+			name: "by jti",
+			revoke: func(s *Service, al, bo domain.User, sessions [3]TokenPair) error {
+				return s.RevokeToken(context.Background(), tokenID(t, s, sessions[0]))
+			},
+			wantValid:   [3]bool{false, true, true},
+			wantRefresh: true,
+		},
+		{
+			name: "unknown jti",
+			revoke: func(s *Service, al, bo domain.User, sessions [3]TokenPair) error {
+				return s.RevokeToken(context.Background(), uuid.NewString())
+			},
+			wantValid:   [3]bool{true, true, true},
+			wantRefresh: true,
+		},
+		{
+			name: "empty jti",
+			revoke: func(s *Service, al, bo domain.User, sessions [3]TokenPair) error {
+				return s.RevokeToken(context.Background(), "")
+			},
+			wantErr:     ErrRevocationTarget,
+			wantValid:   [3]bool{true, true, true},
+			wantRefresh: true,
+		},
+		{
+			name: "by user",
+			revoke: func(s *Service, al, bo domain.User, sessions [3]TokenPair) error {
+				return s.RevokeUser(context.Background(), al.Id)
+			},
+			wantValid: [3]bool{false, false, true},
+		},
+		{
+			name: "unknown user",
+			revoke: func(s *Service, al, bo domain.User, sessions [3]TokenPair) error {
+				return s.RevokeUser(context.Background(), uuid.New())
+			},
+			wantErr:     domain.ErrUserNotFound,
+			wantValid:   [3]bool{true, true, true},
+			wantRefresh: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestService(t, testLockout)
+			al := newTestUser(t, s, "al@example.com", domain.RoleViewer)
+			bo := newTestUser(t, s, "bo@example.com", domain.RoleViewer)
+			sessions := [3]TokenPair{issue(t, s, al), issue(t, s, al), issue(t, s, bo)}
+
+			if err := tt.revoke(s, al, bo, sessions); !errors.Is(err, tt.wantErr) || tt.wantErr == nil && err != nil {
+				t.Fatalf("revoke error = %v, want %v", err, tt.wantErr)
+			}
+
+			for i, session := range sessions {
+				_, err := s.ValidateToken(ctx, session.AccessToken)
+				if valid := err == nil; valid != tt.wantValid[i] {
+					t.Errorf("session %d: access token valid = %v, want %v", i, valid, tt.wantValid[i])
+				}
+				if err != nil && !errors.Is(err, ErrTokenRevoked) {
+					t.Errorf("session %d: error = %v, want ErrTokenRevoked", i, err)
+				}
+			}
+			if _, err := s.Refresh(ctx, sessions[1].RefreshToken); (err == nil) != tt.wantRefresh {
+				t.Errorf("refresh: error = %v, want success %v", err, tt.wantRefresh)
+			}
+			// Revocation never reaches tokens issued after it.
+			if _, err := s.ValidateToken(ctx, issue(t, s, al).AccessToken); err != nil {
+				t.Errorf("token issued after revocation: %v", err)
+			}
+		})
+	}
+}
+
+func TestPruneRevocations(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t, testLockout)
+	user := newTestUser(t, s, "al@example.com", domain.RoleViewer)
+	live := issue(t, s, user)
+
+	// Entries last one access token lifetime; with a negative one they
+	// are expired as soon as they are made.
+	s.accessTokenTTL = -time.Minute
+	if err := s.RevokeToken(ctx, uuid.NewString()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RevokeUser(ctx, user.Id); err != nil {
+		t.Fatal(err)
+	}
+	s.accessTokenTTL = time.Minute
+	if err := s.RevokeToken(ctx, tokenID(t, s, live)); err != nil {
+		t.Fatal(err)
+	}
+
+	pruned, err := s.PruneRevocations(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pruned != 2 {
+		t.Errorf("pruned %d entries, want 2", pruned)
+	}
+	if _, err := s.ValidateToken(ctx, live.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("token of an unexpired entry: error = %v, want ErrTokenRevoked", err)
+	}
+}
+
+func issue(t *testing.T, s *Service, user domain.User) TokenPair {
+	t.Helper()
+	pair, err := s.IssueTokens(context.Background(), user, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pair
+}
+
+// tokenID returns the jti of the access token of pair.
+func tokenID(t *testing.T, s *Service, pair TokenPair) string {
+	t.Helper()
+	claims, err := s.parseToken(pair.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return claims.ID
+}
@@ -1,42 +1,175 @@
 package auth
 
 import (
-	"errors"
+	"context"
+	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/ports"
 )
 
+var (
+	ErrInvalidToken = domain.NewError(domain.ErrUnauthorized, "invalid token")
+	ErrTokenRevoked = domain.NewError(domain.ErrUnauthorized, "token has been revoked")
+)
+
+func init() {
+	// Record token times to the microsecond, so that a token issued right
+	// after its user's tokens were revoked, such as the session returned by
+	// ChangePassword, is not taken for one issued before.
+	jwt.TimePrecision = time.Microsecond
+}
+
+// Config holds the token and login settings of the auth service. Logger
+// receives the audit events for failed logins, lockouts and MFA changes.
+type Config struct {
+	Keys            *KeySet
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	Lockout         LockoutPolicy
+	// MFAIssuer names the service in authenticator apps.
+	MFAIssuer string
+	// RequireAdminMFA keeps admins without a second factor away from
+	// person data.
+	RequireAdminMFA bool
+	Logger          *slog.Logger
+}
+
+// Claims are the JWT claims of an access token. Use is empty for access
+// tokens and names the purpose of any other token signed by the service, so
+// that such tokens are never accepted as access tokens.
+type Claims struct {
+	Role        domain.Role         `json:"role,omitempty"`
+	Permissions []domain.Permission `json:"perms,omitempty"`
+	AMR         []string            `json:"amr,omitempty"`
+	Use         string              `json:"token_use,omitempty"`
+	jwt.RegisteredClaims
+}
+
 type Service struct {
-	secretKey string
+	keys            *KeySet
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+	users           ports.UserRepository
+	refreshTokens   ports.RefreshTokenRepository
+	apiKeys         ports.APIKeyRepository
+	revocations     ports.RevocationRepository
+	loginAttempts   ports.LoginAttemptRepository
+	mfa             ports.MFARepository
+	lockout         LockoutPolicy
+	mfaIssuer       string
+	requireAdminMFA bool
+	logger          *slog.Logger
+}
+
+func NewAuthService(
+	cfg Config,
+	users ports.UserRepository,
+	refreshTokens ports.RefreshTokenRepository,
+	apiKeys ports.APIKeyRepository,
+	revocations ports.RevocationRepository,
+	loginAttempts ports.LoginAttemptRepository,
+	mfa ports.MFARepository,
+) *Service {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{
+		keys:            cfg.Keys,
+		accessTokenTTL:  cfg.AccessTokenTTL,
+		refreshTokenTTL: cfg.RefreshTokenTTL,
+		users:           users,
+		refreshTokens:   refreshTokens,
+		apiKeys:         apiKeys,
+		revocations:     revocations,
+		loginAttempts:   loginAttempts,
+		mfa:             mfa,
+		lockout:         cfg.Lockout,
+		mfaIssuer:       cfg.MFAIssuer,
+		requireAdminMFA: cfg.RequireAdminMFA,
+		logger:          logger,
+	}
 }
 
-func NewAuthService(secretKey string) *Service {
-	return &Service{secretKey: secretKey}
+// AccessTokenTTL returns how long issued access tokens remain valid.
+func (s *Service) AccessTokenTTL() time.Duration {
+	return s.accessTokenTTL
 }
 
-func (s *Service) GenerateToken(userID string) (string, error) {
-	claims := jwt.MapClaims{
-		"sub": userID,
-		"exp": time.Now().Add(24 * time.Hour).Unix(),
+// JWKS returns the public keys that verify tokens issued by this service.
+func (s *Service) JWKS() JWKS {
+	return s.keys.JWKS()
+}
+
+// GenerateToken signs an access token carrying the user's role and the
+// permissions it grants. mfa records that the user passed a second factor.
+func (s *Service) GenerateToken(user domain.User, mfa bool) (string, error) {
+	amr := []string{"pwd"}
+	if mfa {
+		amr = append(amr, amrOTP)
+	}
+
+	now := time.Now()
+	claims := Claims{
+		Role:        user.Role,
+		Permissions: user.Role.Permissions(),
+		AMR:         amr,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   user.Id.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTokenTTL)),
+		},
+	}
+	return s.keys.sign(claims)
+}
+
+// ValidateToken verifies an access token, checks it has not been revoked and
+// returns its principal.
+func (s *Service) ValidateToken(ctx context.Context, tokenString string) (Principal, error) {
+	claims, err := s.parseToken(tokenString)
+	if err != nil {
+		return Principal{}, err
+	}
+	if claims.Use != "" {
+		return Principal{}, ErrInvalidToken
+	}
+
+	revoked, err := s.isRevoked(ctx, claims)
+	if err != nil {
+		return Principal{}, err
+	}
+	if revoked {
+		return Principal{}, ErrTokenRevoked
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.secretKey))
+	return Principal{
+		Subject:     claims.Subject,
+		Role:        claims.Role,
+		Permissions: claims.Permissions,
+		MFA:         slices.Contains(claims.AMR, amrOTP),
+	}, nil
 }
 
-func (s *Service) ValidateToken(tokenString string) (string, error) {
-	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-		return []byte(s.secretKey), nil
-	})
+// parseToken verifies the signature and expiry of a token signed by the
+// service.
+func (s *Service) parseToken(tokenString string) (Claims, error) {
+	var claims Claims
+	token, err := jwt.ParseWithClaims(tokenString, &claims, s.keys.keyFunc,
+		jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}),
+		jwt.WithExpirationRequired(),
+	)
 
 	if err != nil || !token.Valid {
-		return "", errors.New("invalid token")
+		return Claims{}, ErrInvalidToken
 	}
 
-	if claims, ok := token.Claims.(jwt.MapClaims); ok {
-		if sub, ok := claims["sub"].(string); ok {
-			return sub, nil
-		}
+	if claims.Subject == "" || claims.ID == "" {
+		return Claims{}, ErrInvalidToken
 	}
-	return "", errors.New("invalid claims")
+	return claims, nil
 }
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/repository"
+)
+
+const testPassword = "correct horse"
+
+// testLockout locks an account after three failures and an IP after five,
+// without backoff between them.
+var testLockout = LockoutPolicy{MaxAttempts: 3, MaxIPAttempts: 5, Duration: time.Hour}
+
+// newTestService builds a service over in-memory stores.
+func newTestService(t *testing.T, lockout LockoutPolicy) *Service {
+	t.Helper()
+	keys, err := GenerateKeySet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewAuthService(
+		Config{
+			Keys:            keys,
+			AccessTokenTTL:  time.Minute,
+			RefreshTokenTTL: time.Hour,
+			Lockout:         lockout,
+			MFAIssuer:       "Test",
+			Logger:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+		},
+		repository.NewInMemoryUserStore(),
+		repository.NewInMemoryRefreshTokenStore(),
+		repository.NewInMemoryAPIKeyStore(),
+		repository.NewInMemoryRevocationStore(),
+		repository.NewInMemoryLoginAttemptStore(),
+		repository.NewInMemoryMFAStore(),
+	)
+}
+
+// newTestUser registers email with testPassword and role.
+func newTestUser(t *testing.T, s *Service, email string, role domain.Role) domain.User {
+	t.Helper()
+	user, err := s.createUser(context.Background(), email, testPassword, role)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
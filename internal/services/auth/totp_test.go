@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key of the RFC 6238 test vectors,
+// "12345678901234567890", in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateTOTP(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		now      int64
+		wantStep int64
+		wantOK   bool
+	}{
+		// The RFC vectors are 8 digits; 6-digit codes are their last 6.
+		{name: "rfc vector at 59", secret: rfcSecret, code: "287082", now: 59, wantStep: 1, wantOK: true},
+		{name: "rfc vector at 1111111109", secret: rfcSecret, code: "081804", now: 1111111109, wantStep: 37037036, wantOK: true},
+		{name: "rfc vector at 1234567890", secret: rfcSecret, code: "005924", now: 1234567890, wantStep: 41152263, wantOK: true},
+		{name: "rfc vector at 2000000000", secret: rfcSecret, code: "279037", now: 2000000000, wantStep: 66666666, wantOK: true},
+		{name: "previous step accepted", secret: rfcSecret, code: "287082", now: 59 + totpPeriod, wantStep: 1, wantOK: true},
+		{name: "next step accepted", secret: rfcSecret, code: "287082", now: 59 - totpPeriod, wantStep: 1, wantOK: true},
+		{name: "two steps late", secret: rfcSecret, code: "287082", now: 59 + 2*totpPeriod},
+		{name: "wrong code", secret: rfcSecret, code: "287083", now: 59},
+		{name: "eight digits", secret: rfcSecret, code: "94287082", now: 59},
+		{name: "empty code", secret: rfcSecret, code: "", now: 59},
+		{name: "malformed secret", secret: "not base32!", code: "287082", now: 59},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := validateTOTP(tt.secret, tt.code, time.Unix(tt.now, 0))
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("validateTOTP(%q) = %d, %v; want %d, %v", tt.code, step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestIsTOTPCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"123456", true},
+		{"000000", true},
+		{"12345", false},
+		{"1234567", false},
+		{"12345a", false},
+		{"abcd-efgh", false},
+	}
+	for _, tt := range tests {
+		if got := isTOTPCode(tt.code); got != tt.want {
+			t.Errorf("isTOTPCode(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestNewTOTPSecretRoundTrips(t *testing.T) {
+	secret, err := newTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("secret %q is not base32: %v", secret, err)
+	}
+	code := totpCode(key, now.Unix()/totpPeriod)
+	if _, ok := validateTOTP(secret, code, now); !ok {
+		t.Errorf("code %s of a fresh secret was rejected", code)
+	}
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinPasswordLength is the shortest password accepted on registration or
+// password change.
+const MinPasswordLength = 8
+
+var (
+	ErrInvalidCredentials = domain.NewError(domain.ErrUnauthorized, "invalid credentials")
+	ErrInvalidEmail       = domain.NewError(domain.ErrInvalidInput, "invalid email address")
+	ErrWeakPassword       = domain.NewError(domain.ErrInvalidInput, "password must be at least 8 characters")
+	ErrPasswordTooLong    = domain.NewError(domain.ErrInvalidInput, "password must be at most 72 bytes")
+	ErrInvalidRole        = domain.NewError(domain.ErrInvalidInput, "invalid role")
+)
+
+// Register creates a new account with a bcrypt-hashed password and the
+// default role.
+func (s *Service) Register(ctx context.Context, email, password string) (domain.User, error) {
+	return s.createUser(ctx, email, password, domain.DefaultRole)
+}
+
+func (s *Service) createUser(ctx context.Context, email, password string, role domain.Role) (domain.User, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	now := time.Now().UTC()
+	user := domain.User{
+		Id:           uuid.New(),
+		Email:        email,
+		PasswordHash: hash,
+		Role:         role,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.users.CreateUser(ctx, user); err != nil {
+		return domain.User{}, err
+	}
+	return user, nil
+}
+
+// Authenticate returns the account matching email if password is correct.
+// It does not apply the lockout policy; interactive logins go through Login.
+func (s *Service) Authenticate(ctx context.Context, email, password string) (domain.User, error) {
+	user, err := s.users.GetUserByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			// Spend the same bcrypt work as for a real account.
+			_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+			return domain.User{}, ErrInvalidCredentials
+		}
+		return domain.User{}, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return domain.User{}, ErrInvalidCredentials
+	}
+	return *user, nil
+}
+
+// ChangePassword replaces the password of userID after verifying the
+// current one, which counts towards the lockout policy like a login from
+// clientIP. Every session of the user is then ended, and a new one is
+// returned in its place; mfa carries over the second factor of the session
+// that made the change.
+func (s *Service) ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword, clientIP string, mfa bool) (TokenPair, error) {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	err = s.throttlePasswordCheck(ctx, "password_change_failed", user.Email, clientIP, func() error {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)); err != nil {
+			return ErrInvalidCredentials
+		}
+		return nil
+	})
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	hash, err := hashPassword(newPassword)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if err := s.users.UpdatePassword(ctx, userID, hash); err != nil {
+		return TokenPair{}, err
+	}
+	if err := s.RevokeUser(ctx, userID); err != nil {
+		return TokenPair{}, err
+	}
+	return s.IssueTokens(ctx, *user, mfa)
+}
+
+// SetRole changes the role of userID.
+func (s *Service) SetRole(ctx context.Context, userID uuid.UUID, role domain.Role) error {
+	if !role.Valid() {
+		return ErrInvalidRole
+	}
+	return s.users.UpdateRole(ctx, userID, role)
+}
+
+// EnsureUser registers email with password and role unless an account
+// already exists, in which case it only makes sure the account has role.
+// This lets deployments bootstrap an initial administrator.
+func (s *Service) EnsureUser(ctx context.Context, email, password string, role domain.Role) (domain.User, bool, error) {
+	existing, err := s.users.GetUserByEmail(ctx, strings.TrimSpace(email))
+	if err == nil {
+		if existing.Role != role {
+			if err := s.SetRole(ctx, existing.Id, role); err != nil {
+				return domain.User{}, false, err
+			}
+			existing.Role = role
+		}
+		return *existing, false, nil
+	}
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		return domain.User{}, false, err
+	}
+
+	user, err := s.createUser(ctx, email, password, role)
+	if err != nil {
+		return domain.User{}, false, err
+	}
+	return user, true, nil
+}
+
+func normalizeEmail(email string) (string, error) {
+	addr, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil || addr.Name != "" {
+		return "", ErrInvalidEmail
+	}
+	return strings.ToLower(addr.Address), nil
+}
+
+func hashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", ErrWeakPassword
+	}
+	// bcrypt silently ignores anything past 72 bytes, so refuse it outright.
+	if len(password) > 72 {
+		return "", ErrPasswordTooLong
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+func TestChangePassword(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t, testLockout)
+	user := newTestUser(t, s, "al@example.com", domain.RoleViewer)
+	old, err := s.IssueTokens(ctx, user, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pair, err := s.ChangePassword(ctx, user.Id, testPassword, "new password", "10.0.0.1", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.ValidateToken(ctx, old.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("old access token: error = %v, want ErrTokenRevoked", err)
+	}
+	if _, err := s.Refresh(ctx, old.RefreshToken); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Errorf("old refresh token: error = %v, want unauthorized", err)
+	}
+	principal, err := s.ValidateToken(ctx, pair.AccessToken)
+	if err != nil {
+		t.Fatalf("new access token: %v", err)
+	}
+	if !principal.MFA {
+		t.Error("new session lost the second factor")
+	}
+	if _, err := s.Refresh(ctx, pair.RefreshToken); err != nil {
+		t.Errorf("new refresh token: %v", err)
+	}
+	if _, err := s.Login(ctx, "al@example.com", "new password", "10.0.0.1"); err != nil {
+		t.Errorf("login with the new password: %v", err)
+	}
+}
+
+func TestChangePasswordLockout(t *testing.T) {
+	tests := []struct {
+		name string
+		// wrong is the number of wrong current passwords sent first.
+		wrong         int
+		wantThrottled bool
+	}{
+		{name: "below the limit", wrong: testLockout.MaxAttempts - 1},
+		{name: "locked out", wrong: testLockout.MaxAttempts, wantThrottled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newTestService(t, testLockout)
+			user := newTestUser(t, s, "al@example.com", domain.RoleViewer)
+			for i := 0; i < tt.wrong; i++ {
+				if _, err := s.ChangePassword(ctx, user.Id, "wrong password", "new password", "10.0.0.1", false); !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf("attempt %d: error = %v, want ErrInvalidCredentials", i, err)
+				}
+			}
+
+			_, err := s.ChangePassword(ctx, user.Id, testPassword, "new password", "10.0.0.1", false)
+			var throttled *LoginThrottledError
+			if errors.As(err, &throttled) != tt.wantThrottled || !tt.wantThrottled && err != nil {
+				t.Fatalf("change with the right password: error = %v, throttled %v", err, tt.wantThrottled)
+			}
+			// The lockout is the account's, shared with logins.
+			if _, err := s.Login(ctx, "al@example.com", testPassword, "10.0.0.2"); errors.As(err, &throttled) != tt.wantThrottled {
+				t.Errorf("login: error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package idempotency
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/ports"
+)
+
+// ReservationTimeout bounds how long a request holds its key. It outlasts
+// the server's write timeout, so only a request that was abandoned, e.g.
+// by a crash, loses its key to a retry.
+const ReservationTimeout = time.Minute
+
+// Service remembers the responses of requests sent with an Idempotency-Key,
+// so that a retry gets the response of the first request instead of
+// repeating it.
+type Service struct {
+	repo ports.IdempotencyRepository
+	ttl  time.Duration
+}
+
+// NewIdempotencyService returns a Service that replays responses for ttl.
+func NewIdempotencyService(repo ports.IdempotencyRepository, ttl time.Duration) *Service {
+	return &Service{repo: repo, ttl: ttl}
+}
+
+// Begin reserves key of principal for the request with requestHash. When
+// the same request already completed under key it returns its record to
+// replay. It fails with domain.ErrIdempotencyKeyMismatch when key was used
+// for another request and with domain.ErrIdempotencyKeyInUse while the
+// same request is still in progress.
+func (s *Service) Begin(ctx context.Context, principal, key, requestHash string) (*domain.IdempotencyRecord, error) {
+	now := time.Now()
+	record, reserved, err := s.repo.ReserveIdempotencyKey(ctx, domain.IdempotencyRecord{
+		Principal:   principal,
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ReservationTimeout),
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case reserved:
+		return nil, nil
+	case record.RequestHash != requestHash:
+		return nil, domain.ErrIdempotencyKeyMismatch
+	case !record.Completed():
+		return nil, domain.ErrIdempotencyKeyInUse
+	default:
+		return &record, nil
+	}
+}
+
+// Complete stores the response to the request that reserved key, to be
+// replayed for the retries of the next ttl.
+func (s *Service) Complete(ctx context.Context, principal, key string, statusCode int, contentType string, body []byte) error {
+	return s.repo.CompleteIdempotencyKey(ctx, domain.IdempotencyRecord{
+		Principal:   principal,
+		Key:         key,
+		StatusCode:  statusCode,
+		ContentType: contentType,
+		Body:        body,
+		ExpiresAt:   time.Now().Add(s.ttl),
+	})
+}
+
+// Release frees key after a request that should not be replayed, such as
+// one that failed on the server, so that a retry runs again.
+func (s *Service) Release(ctx context.Context, principal, key string) error {
+	return s.repo.ReleaseIdempotencyKey(ctx, principal, key)
+}
+
+// PruneIdempotencyKeys drops expired records.
+func (s *Service) PruneIdempotencyKeys(ctx context.Context) (int64, error) {
+	return s.repo.PruneIdempotencyKeys(ctx, time.Now())
+}
+
+// RunPruner calls PruneIdempotencyKeys every interval until ctx is done.
+func (s *Service) RunPruner(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pruned, err := s.PruneIdempotencyKeys(ctx)
+			if err != nil {
+				logger.Error("failed to prune idempotency keys", "error", err)
+				continue
+			}
+			if pruned > 0 {
+				logger.Info("pruned expired idempotency keys", "count", pruned)
+			}
+		}
+	}
+}
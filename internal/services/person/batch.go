@@ -0,0 +1,118 @@
+package person_service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// MaxBatchSize bounds the number of persons in one batch write.
+const MaxBatchSize = 5000
+
+var (
+	ErrEmptyBatch         = domain.NewError(domain.ErrInvalidInput, "batch contains no persons")
+	ErrBatchTooLarge      = domain.NewError(domain.ErrTooLarge, fmt.Sprintf("batch exceeds %d persons", MaxBatchSize))
+	ErrBatchItemID        = domain.NewError(domain.ErrValidation, "person id is required")
+	ErrDuplicateBatchItem = domain.NewError(domain.ErrValidation, "person appears more than once in the batch")
+)
+
+// CreatePersons creates persons, assigning ids to those without one. When
+// atomic is set either all of them are created or none is. It returns one
+// result per person, in order.
+func (s *PersonServiceStore) CreatePersons(ctx context.Context, persons []domain.Person, atomic bool) ([]domain.BatchResult, error) {
+	createdAt := now()
+	for i := range persons {
+		if persons[i].Id == uuid.Nil {
+			persons[i].Id = uuid.New()
+		}
+		persons[i].CreatedAt = createdAt
+		persons[i].UpdatedAt = createdAt
+		persons[i].Version = 1
+	}
+	return s.writeBatch(persons, atomic, true, func(persons []domain.Person) ([]domain.BatchResult, error) {
+		return s.PersonRepo.CreatePersons(ctx, persons, atomic)
+	})
+}
+
+// UpdatePersons replaces persons, each conditional on its Version unless
+// it is 0, like UpdatePerson.
+func (s *PersonServiceStore) UpdatePersons(ctx context.Context, persons []domain.Person, atomic bool) ([]domain.BatchResult, error) {
+	updatedAt := now()
+	for i := range persons {
+		persons[i].UpdatedAt = updatedAt
+	}
+	return s.writeBatch(persons, atomic, true, func(persons []domain.Person) ([]domain.BatchResult, error) {
+		return s.PersonRepo.UpdatePersons(ctx, persons, atomic)
+	})
+}
+
+// DeletePersons deletes the persons with the given ids, each conditional on
+// its Version unless it is 0, like DeletePerson.
+func (s *PersonServiceStore) DeletePersons(ctx context.Context, persons []domain.Person, atomic bool) ([]domain.BatchResult, error) {
+	deletedAt := now()
+	return s.writeBatch(persons, atomic, false, func(persons []domain.Person) ([]domain.BatchResult, error) {
+		return s.PersonRepo.DeletePersons(ctx, persons, deletedAt, atomic)
+	})
+}
+
+// writeBatch checks every person of a batch and passes the ones that pass
+// to write. Checks fail before the repository is involved: an atomic batch
+// with a failed check is not written at all.
+func (s *PersonServiceStore) writeBatch(persons []domain.Person, atomic, validate bool, write func([]domain.Person) ([]domain.BatchResult, error)) ([]domain.BatchResult, error) {
+	if len(persons) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if len(persons) > MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	results := make([]domain.BatchResult, len(persons))
+	seen := make(map[uuid.UUID]bool, len(persons))
+	var (
+		pending []int
+		failed  bool
+	)
+	for i, person := range persons {
+		results[i].Person = person
+		switch {
+		case person.Id == uuid.Nil:
+			results[i].Err = ErrBatchItemID
+		case seen[person.Id]:
+			results[i].Err = ErrDuplicateBatchItem
+		case validate:
+			results[i].Err = person.Validate()
+		}
+		seen[person.Id] = true
+
+		if results[i].Err != nil {
+			failed = true
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	if failed && atomic {
+		for _, i := range pending {
+			results[i].Err = domain.ErrBatchAborted
+		}
+		return results, nil
+	}
+	if len(pending) == 0 {
+		return results, nil
+	}
+
+	valid := make([]domain.Person, len(pending))
+	for j, i := range pending {
+		valid[j] = persons[i]
+	}
+	written, err := write(valid)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range pending {
+		results[i] = written[j]
+	}
+	return results, nil
+}
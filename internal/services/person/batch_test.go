@@ -0,0 +1,91 @@
+package person_service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/repository"
+)
+
+func TestWriteBatchSize(t *testing.T) {
+	svc := NewPersonSvc(repository.NewInMemoryUserRepo(), cursorSecret)
+	tests := []struct {
+		name     string
+		size     int
+		wantErr  error
+		wantKind error
+	}{
+		{name: "empty", size: 0, wantErr: ErrEmptyBatch, wantKind: domain.ErrInvalidInput},
+		{name: "largest", size: MaxBatchSize},
+		{name: "too large", size: MaxBatchSize + 1, wantErr: ErrBatchTooLarge, wantKind: domain.ErrTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			persons := make([]domain.Person, tt.size)
+			for i := range persons {
+				persons[i] = domain.Person{Name: "Al", Age: 1}
+			}
+			_, err := svc.CreatePersons(context.Background(), persons, true)
+			if !errors.Is(err, tt.wantErr) || tt.wantErr == nil && err != nil {
+				t.Fatalf("CreatePersons of %d persons: error = %v, want %v", tt.size, err, tt.wantErr)
+			}
+			if tt.wantKind != nil && !errors.Is(err, tt.wantKind) {
+				t.Errorf("error %v is not of kind %v", err, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestWriteBatchChecks(t *testing.T) {
+	id := uuid.New()
+	batch := []domain.Person{
+		{Id: uuid.New(), Name: "Al", Age: 1},
+		{Name: "No Id", Age: 1},
+		{Id: id, Name: "Bo", Age: 1},
+		{Id: id, Name: "Bo Again", Age: 1},
+		{Id: uuid.New(), Name: "", Age: -1},
+	}
+	// Checks fail before the repository is involved, so an atomic batch
+	// with a failed check writes nothing.
+	tests := []struct {
+		name   string
+		atomic bool
+		want   []error
+	}{
+		{name: "best effort", want: []error{nil, ErrBatchItemID, nil, ErrDuplicateBatchItem, domain.ErrValidation}},
+		{name: "atomic", atomic: true, want: []error{domain.ErrBatchAborted, ErrBatchItemID, domain.ErrBatchAborted, ErrDuplicateBatchItem, domain.ErrValidation}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := repository.NewInMemoryUserRepo()
+			svc := NewPersonSvc(repo, cursorSecret)
+			for _, person := range batch[:3] {
+				if person.Id != uuid.Nil {
+					repo.CreatePerson(ctx, domain.Person{Id: person.Id, Name: "Old", Age: 1, Version: 1})
+				}
+			}
+
+			results, err := svc.UpdatePersons(ctx, append([]domain.Person(nil), batch...), tt.atomic)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, result := range results {
+				if tt.want[i] == nil && result.Err != nil || tt.want[i] != nil && !errors.Is(result.Err, tt.want[i]) {
+					t.Errorf("result %d error = %v, want %v", i, result.Err, tt.want[i])
+				}
+			}
+
+			stored, err := repo.GetPerson(ctx, batch[0].Id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := map[bool]string{false: "Al", true: "Old"}[tt.atomic]; stored.Name != want {
+				t.Errorf("stored name = %q, want %q", stored.Name, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package person_service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// cursorPayload is the wire form of a domain.PersonCursor. Fields that do
+// not belong to the cursor's sort order are left out to keep it short.
+type cursorPayload struct {
+	SortBy    domain.PersonSortField `json:"s"`
+	SortDesc  bool                   `json:"d,omitempty"`
+	Before    bool                   `json:"b,omitempty"`
+	Id        uuid.UUID              `json:"i"`
+	Name      string                 `json:"n,omitempty"`
+	Age       int                    `json:"a,omitempty"`
+	CreatedAt *time.Time             `json:"c,omitempty"`
+	UpdatedAt *time.Time             `json:"u,omitempty"`
+}
+
+// encodeCursor serializes cursor as base64url(JSON).base64url(HMAC) so that
+// clients can pass it around but not forge positions.
+func encodeCursor(secret []byte, cursor domain.PersonCursor) string {
+	payload := cursorPayload{
+		SortBy:   cursor.SortBy,
+		SortDesc: cursor.SortDesc,
+		Before:   cursor.Before,
+		Id:       cursor.Id,
+		Name:     cursor.Name,
+		Age:      cursor.Age,
+	}
+	if !cursor.CreatedAt.IsZero() {
+		payload.CreatedAt = &cursor.CreatedAt
+	}
+	if !cursor.UpdatedAt.IsZero() {
+		payload.UpdatedAt = &cursor.UpdatedAt
+	}
+
+	data, _ := json.Marshal(payload)
+	body := base64.RawURLEncoding.EncodeToString(data)
+	return body + "." + base64.RawURLEncoding.EncodeToString(signCursor(secret, body))
+}
+
+// decodeCursor verifies and parses a cursor made by encodeCursor.
+func decodeCursor(secret []byte, raw string) (domain.PersonCursor, error) {
+	body, sig, ok := strings.Cut(raw, ".")
+	if !ok {
+		return domain.PersonCursor{}, domain.ErrInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, signCursor(secret, body)) {
+		return domain.PersonCursor{}, domain.ErrInvalidCursor
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return domain.PersonCursor{}, domain.ErrInvalidCursor
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil || !payload.SortBy.Valid() {
+		return domain.PersonCursor{}, domain.ErrInvalidCursor
+	}
+
+	cursor := domain.PersonCursor{
+		SortBy:   payload.SortBy,
+		SortDesc: payload.SortDesc,
+		Before:   payload.Before,
+		Id:       payload.Id,
+		Name:     payload.Name,
+		Age:      payload.Age,
+	}
+	if payload.CreatedAt != nil {
+		cursor.CreatedAt = *payload.CreatedAt
+	}
+	if payload.UpdatedAt != nil {
+		cursor.UpdatedAt = *payload.UpdatedAt
+	}
+	return cursor, nil
+}
+
+func signCursor(secret []byte, body string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(body))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,71 @@
+package person_service
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+var cursorSecret = []byte("cursor-test-secret")
+
+func TestCursorRoundTrip(t *testing.T) {
+	id := uuid.MustParse("0d7c4f3e-6b1a-4f53-9c43-7f6f0e5e2a10")
+	at := time.Date(2024, 5, 17, 9, 30, 0, 123456000, time.UTC)
+	tests := []struct {
+		name   string
+		cursor domain.PersonCursor
+	}{
+		{name: "created at", cursor: domain.PersonCursor{SortBy: domain.SortByCreatedAt, SortDesc: true, Id: id, CreatedAt: at}},
+		{name: "updated at before", cursor: domain.PersonCursor{SortBy: domain.SortByUpdatedAt, Before: true, Id: id, UpdatedAt: at}},
+		{name: "name", cursor: domain.PersonCursor{SortBy: domain.SortByName, Id: id, Name: "Zoë O'Brien"}},
+		{name: "age zero", cursor: domain.PersonCursor{SortBy: domain.SortByAge, SortDesc: true, Before: true, Id: id}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := encodeCursor(cursorSecret, tt.cursor)
+			got, err := decodeCursor(cursorSecret, raw)
+			if err != nil {
+				t.Fatalf("decodeCursor(%q): %v", raw, err)
+			}
+			if !reflect.DeepEqual(got, tt.cursor) {
+				t.Errorf("decodeCursor(encodeCursor(%+v)) = %+v", tt.cursor, got)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	valid := encodeCursor(cursorSecret, domain.PersonCursor{SortBy: domain.SortByName, Id: uuid.New(), Name: "Al"})
+	body, sig, _ := strings.Cut(valid, ".")
+
+	forged := func(payload string) string {
+		body := base64.RawURLEncoding.EncodeToString([]byte(payload))
+		return body + "." + base64.RawURLEncoding.EncodeToString(signCursor(cursorSecret, body))
+	}
+	tests := []struct {
+		name   string
+		secret []byte
+		raw    string
+	}{
+		{name: "empty", secret: cursorSecret, raw: ""},
+		{name: "no signature", secret: cursorSecret, raw: body},
+		{name: "other secret", secret: []byte("another secret"), raw: valid},
+		{name: "tampered body", secret: cursorSecret, raw: body + "x." + sig},
+		{name: "malformed signature", secret: cursorSecret, raw: body + ".!!"},
+		{name: "signed garbage", secret: cursorSecret, raw: forged("not json")},
+		{name: "signed unknown sort field", secret: cursorSecret, raw: forged(`{"s":"hobbies","i":"` + uuid.NewString() + `"}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.secret, tt.raw); !errors.Is(err, domain.ErrInvalidCursor) {
+				t.Errorf("decodeCursor(%q) error = %v, want ErrInvalidCursor", tt.raw, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package person_service
+
+import (
+	"context"
+	"io"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// ExportPageSize is the number of persons read per query while exporting.
+const ExportPageSize = 500
+
+// importChunk is the number of rows created per batch while importing.
+const importChunk = 500
+
+// ImportReport sums up an import. Rejected lists the rows that were not
+// imported, in input order.
+type ImportReport struct {
+	Imported int
+	Rejected []RejectedRow
+}
+
+// RejectedRow is an input row that was not imported. Id is the person id
+// of the row, if it had one.
+type RejectedRow struct {
+	Row int
+	Id  uuid.UUID
+	Err error
+}
+
+// ExportPersons passes every person matching query to fn, in the order of
+// query. Persons are read a page at a time by cursor, so the export neither
+// holds all of them in memory nor skips rows inserted while it runs.
+func (s *PersonServiceStore) ExportPersons(ctx context.Context, query domain.PersonQuery, fn func(domain.Person) error) error {
+	query.Limit = ExportPageSize
+	query.Offset = 0
+
+	cursor := ""
+	for {
+		page, err := s.ListPersons(ctx, query, cursor)
+		if err != nil {
+			return err
+		}
+		for _, person := range page.Persons {
+			if err := fn(person); err != nil {
+				return err
+			}
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// ImportPersons creates the persons read from r in format, rejecting rows
+// that cannot be read or created while importing the others. Rows are
+// created in batches as they are read; when an error stops the import, the
+// batches before it stay imported and the report counts them.
+func (s *PersonServiceStore) ImportPersons(ctx context.Context, r io.Reader, format PersonFormat) (ImportReport, error) {
+	reader, err := NewPersonReader(r, format)
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	var (
+		report  ImportReport
+		persons []domain.Person
+		rows    []int
+	)
+	flush := func() error {
+		if len(persons) == 0 {
+			return nil
+		}
+		results, err := s.CreatePersons(ctx, persons, false)
+		if err != nil {
+			return err
+		}
+		for i, result := range results {
+			if result.Err != nil {
+				report.Rejected = append(report.Rejected, RejectedRow{Row: rows[i], Id: result.Person.Id, Err: result.Err})
+				continue
+			}
+			report.Imported++
+		}
+		persons, rows = persons[:0], rows[:0]
+		return nil
+	}
+	// Rows that cannot be read are rejected before the batch of the rows
+	// preceding them is created, hence the sort.
+	done := func(err error) (ImportReport, error) {
+		slices.SortFunc(report.Rejected, func(a, b RejectedRow) int { return a.Row - b.Row })
+		return report, err
+	}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return done(err)
+		}
+		if row.Err == nil {
+			// Validated here rather than by CreatePersons, which would
+			// have assigned the person an id by then.
+			row.Err = row.Person.Validate()
+		}
+		if row.Err != nil {
+			report.Rejected = append(report.Rejected, RejectedRow{Row: row.Row, Id: row.Person.Id, Err: row.Err})
+			continue
+		}
+
+		persons = append(persons, row.Person)
+		rows = append(rows, row.Row)
+		if len(persons) == importChunk {
+			if err := flush(); err != nil {
+				return done(err)
+			}
+		}
+	}
+	return done(flush())
+}
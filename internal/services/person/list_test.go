@@ -0,0 +1,96 @@
+package person_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/repository"
+)
+
+// TestListPersonsPages walks the pages of a list forwards by NextCursor and
+// back by PrevCursor.
+func TestListPersonsPages(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepo()
+	var want []string
+	for age := 0; age < 7; age++ {
+		name := fmt.Sprintf("Person %c", 'A'+age)
+		if err := repo.CreatePerson(ctx, domain.Person{Id: uuid.New(), Name: name, Age: age, Version: 1}); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, name)
+	}
+	svc := NewPersonSvc(repo, cursorSecret)
+	query := domain.PersonQuery{SortBy: domain.SortByAge, Limit: 3}
+
+	pageNames := func(page PersonPage) []string {
+		var names []string
+		for _, person := range page.Persons {
+			names = append(names, person.Name)
+		}
+		return names
+	}
+
+	var pages []PersonPage
+	cursor := ""
+	for {
+		page, err := svc.ListPersons(ctx, query, cursor)
+		if err != nil {
+			t.Fatalf("ListPersons(%q): %v", cursor, err)
+		}
+		pages = append(pages, page)
+		if page.NextCursor == "" {
+			break
+		}
+		if len(pages) > len(want) {
+			t.Fatal("pagination does not end")
+		}
+		cursor = page.NextCursor
+	}
+
+	wantPages := [][]string{want[0:3], want[3:6], want[6:7]}
+	if len(pages) != len(wantPages) {
+		t.Fatalf("got %d pages, want %d", len(pages), len(wantPages))
+	}
+	for i, page := range pages {
+		if got := pageNames(page); !reflect.DeepEqual(got, wantPages[i]) {
+			t.Errorf("page %d = %v, want %v", i, got, wantPages[i])
+		}
+		if (page.PrevCursor == "") != (i == 0) {
+			t.Errorf("page %d has PrevCursor %q", i, page.PrevCursor)
+		}
+	}
+
+	for i := len(pages) - 1; i > 0; i-- {
+		page, err := svc.ListPersons(ctx, query, pages[i].PrevCursor)
+		if err != nil {
+			t.Fatalf("ListPersons(prev of page %d): %v", i, err)
+		}
+		if got := pageNames(page); !reflect.DeepEqual(got, wantPages[i-1]) {
+			t.Errorf("page before %d = %v, want %v", i, got, wantPages[i-1])
+		}
+	}
+}
+
+func TestListPersonsRejectsCursorOfAnotherOrder(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepo()
+	for age := 0; age < 3; age++ {
+		repo.CreatePerson(ctx, domain.Person{Id: uuid.New(), Name: "Al", Age: age, Version: 1})
+	}
+	svc := NewPersonSvc(repo, cursorSecret)
+
+	page, err := svc.ListPersons(ctx, domain.PersonQuery{SortBy: domain.SortByAge, Limit: 1}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = svc.ListPersons(ctx, domain.PersonQuery{SortBy: domain.SortByName, Limit: 1}, page.NextCursor)
+	if !errors.Is(err, domain.ErrCursorMismatch) {
+		t.Errorf("ListPersons with a cursor of another order: error = %v, want ErrCursorMismatch", err)
+	}
+}
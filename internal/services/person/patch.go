@@ -0,0 +1,138 @@
+package person_service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// PatchType is the media type of a person patch document.
+type PatchType string
+
+const (
+	// MergePatch is an RFC 7396 JSON Merge Patch.
+	MergePatch PatchType = "application/merge-patch+json"
+	// JSONPatch is an RFC 6902 JSON Patch.
+	JSONPatch PatchType = "application/json-patch+json"
+)
+
+var (
+	ErrUnsupportedPatchType = errors.New("unsupported patch media type")
+	ErrInvalidPatch         = domain.NewError(domain.ErrInvalidInput, "malformed patch document")
+	ErrPatchTestFailed      = domain.NewError(domain.ErrConflict, "patch test operation failed")
+	ErrPatchNotApplicable   = domain.NewError(domain.ErrValidation, "patch cannot be applied to the person")
+)
+
+// patchDocument is the JSON form of a person that patches are applied to.
+// Its members match PersonRequest, so paths are the ones clients send.
+type patchDocument struct {
+	Name    string   `json:"name"`
+	Age     int      `json:"age"`
+	Hobbies []string `json:"hobbies"`
+}
+
+// patchAttempts bounds how often an unconditional patch is re-applied when
+// the person changes between reading and writing it.
+const patchAttempts = 3
+
+// PatchPerson applies patch, a document of patchType, to the stored person
+// and saves only the fields it changed. A non-zero version makes the patch
+// conditional on it. It returns the patched person.
+func (s *PersonServiceStore) PatchPerson(ctx context.Context, id uuid.UUID, version int64, patchType PatchType, patch []byte) (domain.Person, error) {
+	for attempt := 1; ; attempt++ {
+		person, err := s.patchPerson(ctx, id, version, patchType, patch)
+		if errors.Is(err, domain.ErrPersonVersionMismatch) && version == 0 && attempt < patchAttempts {
+			continue
+		}
+		return person, err
+	}
+}
+
+// patchPerson applies patch to the person as read once. The write is
+// conditional on the version read, so a concurrent change is never
+// overwritten with a patch computed from stale data.
+func (s *PersonServiceStore) patchPerson(ctx context.Context, id uuid.UUID, version int64, patchType PatchType, patch []byte) (domain.Person, error) {
+	current, err := s.GetPerson(ctx, id)
+	if err != nil {
+		return domain.Person{}, err
+	}
+	if version != 0 && current.Version != version {
+		return domain.Person{}, domain.ErrPersonVersionMismatch
+	}
+
+	original, err := json.Marshal(patchDocument{
+		Name:    current.Name,
+		Age:     current.Age,
+		Hobbies: current.Hobbies,
+	})
+	if err != nil {
+		return domain.Person{}, err
+	}
+	patched, err := applyPatch(patchType, original, patch)
+	if err != nil {
+		return domain.Person{}, err
+	}
+
+	// Unknown members catch paths that do not exist on a person, such as
+	// an attempt to change the id.
+	var doc patchDocument
+	dec := json.NewDecoder(bytes.NewReader(patched))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&doc); err != nil {
+		return domain.Person{}, fmt.Errorf("%w: %v", ErrPatchNotApplicable, err)
+	}
+	if doc.Hobbies == nil {
+		doc.Hobbies = []string{}
+	}
+
+	next := current
+	next.Name = doc.Name
+	next.Age = doc.Age
+	next.Hobbies = doc.Hobbies
+	if err := next.Validate(); err != nil {
+		return domain.Person{}, err
+	}
+
+	fields := current.ChangedFields(next)
+	if len(fields) == 0 {
+		return current, nil
+	}
+	next.UpdatedAt = now()
+	updated, err := s.PersonRepo.PatchPerson(ctx, next, fields)
+	if err != nil {
+		return domain.Person{}, err
+	}
+	return *updated, nil
+}
+
+func applyPatch(patchType PatchType, original, patch []byte) ([]byte, error) {
+	switch patchType {
+	case MergePatch:
+		patched, err := jsonpatch.MergePatch(original, patch)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		return patched, nil
+	case JSONPatch:
+		ops, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		patched, err := ops.Apply(original)
+		switch {
+		case errors.Is(err, jsonpatch.ErrTestFailed):
+			return nil, fmt.Errorf("%w: %v", ErrPatchTestFailed, err)
+		case err != nil:
+			return nil, fmt.Errorf("%w: %v", ErrPatchNotApplicable, err)
+		}
+		return patched, nil
+	default:
+		return nil, ErrUnsupportedPatchType
+	}
+}
@@ -0,0 +1,47 @@
+package person_service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// RestorePerson brings back a deleted person that has not been purged yet.
+func (s *PersonServiceStore) RestorePerson(ctx context.Context, id uuid.UUID) (domain.Person, error) {
+	restored, err := s.PersonRepo.RestorePerson(ctx, id, now())
+	if err != nil {
+		return domain.Person{}, err
+	}
+	return *restored, nil
+}
+
+// PurgeDeletedPersons hard-deletes persons that were deleted more than
+// retention ago.
+func (s *PersonServiceStore) PurgeDeletedPersons(ctx context.Context, retention time.Duration) (int64, error) {
+	return s.PersonRepo.PurgePersons(ctx, now().Add(-retention))
+}
+
+// RunPurger calls PurgeDeletedPersons every interval until ctx is done.
+func (s *PersonServiceStore) RunPurger(ctx context.Context, interval, retention time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.PurgeDeletedPersons(ctx, retention)
+			if err != nil {
+				logger.Error("failed to purge deleted persons", "error", err)
+				continue
+			}
+			if purged > 0 {
+				logger.Info("purged deleted persons", "count", purged)
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package person_service
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
@@ -9,19 +10,93 @@ import (
 )
 
 type PersonServiceStore struct {
-	PersonRepo ports.PersonRepository
+	PersonRepo   ports.PersonRepository
+	cursorSecret []byte
 }
 
-func NewPersonSvc(personRepo ports.PersonRepository) PersonServiceAbstrcatImpl {
-	return &PersonServiceStore{PersonRepo: personRepo}
+// NewPersonSvc builds the person service. cursorSecret signs the list
+// cursors handed to clients.
+func NewPersonSvc(personRepo ports.PersonRepository, cursorSecret []byte) PersonServiceAbstrcatImpl {
+	return &PersonServiceStore{PersonRepo: personRepo, cursorSecret: cursorSecret}
+}
+
+// PersonPage is one page of the person list. NextCursor and PrevCursor lead
+// to the neighbouring pages and are empty when there is none.
+type PersonPage struct {
+	Persons    []domain.Person
+	NextCursor string
+	PrevCursor string
 }
 
 func (s *PersonServiceStore) CreatePerson(ctx context.Context, person domain.Person) error {
+	if err := person.Validate(); err != nil {
+		return err
+	}
+	person.CreatedAt = now()
+	person.UpdatedAt = person.CreatedAt
+	person.Version = 1
 	return s.PersonRepo.CreatePerson(ctx, person)
 }
 
-func (s *PersonServiceStore) GetAllPersons(ctx context.Context, limit, offset int) ([]domain.Person, error) {
-	return s.PersonRepo.GetAllPersons(ctx, limit, offset)
+func (s *PersonServiceStore) GetAllPersons(ctx context.Context, query domain.PersonQuery) ([]domain.Person, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	return s.PersonRepo.GetAllPersons(ctx, query)
+}
+
+// ListPersons returns the page of query that starts at cursor, or at
+// query.Offset when cursor is empty.
+func (s *PersonServiceStore) ListPersons(ctx context.Context, query domain.PersonQuery, cursor string) (PersonPage, error) {
+	if cursor != "" {
+		position, err := decodeCursor(s.cursorSecret, cursor)
+		if err != nil {
+			return PersonPage{}, err
+		}
+		query.Cursor = &position
+	}
+	if err := query.Validate(); err != nil {
+		return PersonPage{}, err
+	}
+
+	// One extra row tells whether there is a page beyond this one.
+	limit := query.Limit
+	query.Limit++
+	persons, err := s.PersonRepo.GetAllPersons(ctx, query)
+	if err != nil {
+		return PersonPage{}, err
+	}
+
+	backward := query.Cursor != nil && query.Cursor.Before
+	more := len(persons) > limit
+	if more {
+		if backward {
+			persons = persons[1:]
+		} else {
+			persons = persons[:limit]
+		}
+	}
+
+	page := PersonPage{Persons: persons}
+	if len(persons) == 0 {
+		return page, nil
+	}
+
+	hasNext := more || backward
+	hasPrev := (more && backward) || (!backward && (query.Cursor != nil || query.Offset > 0))
+	if hasNext {
+		page.NextCursor = encodeCursor(s.cursorSecret, query.CursorAt(persons[len(persons)-1], false))
+	}
+	if hasPrev {
+		page.PrevCursor = encodeCursor(s.cursorSecret, query.CursorAt(persons[0], true))
+	}
+	return page, nil
+}
+
+// CountPersons returns the number of persons matching filter across all
+// pages.
+func (s *PersonServiceStore) CountPersons(ctx context.Context, filter domain.PersonFilter) (int, error) {
+	return s.PersonRepo.CountPersons(ctx, filter)
 }
 
 func (s *PersonServiceStore) GetPerson(ctx context.Context, id uuid.UUID) (domain.Person, error) {
@@ -30,17 +105,54 @@ func (s *PersonServiceStore) GetPerson(ctx context.Context, id uuid.UUID) (domai
 		return domain.Person{}, err
 	}
 	return domain.Person{
-		Id:      data.Id,
-		Name:    data.Name,
-		Age:     data.Age,
-		Hobbies: data.Hobbies,
+		Id:        data.Id,
+		Name:      data.Name,
+		Age:       data.Age,
+		Hobbies:   data.Hobbies,
+		CreatedAt: data.CreatedAt,
+		UpdatedAt: data.UpdatedAt,
+		Version:   data.Version,
+		DeletedAt: data.DeletedAt,
 	}, nil
 }
 
-func (s *PersonServiceStore) UpdatePerson(ctx context.Context, person domain.Person) error {
-	return s.PersonRepo.UpdatePerson(ctx, person)
+// UpdatePerson replaces a person. A non-zero person.Version makes the
+// update conditional on it, see ports.PersonRepository.
+func (s *PersonServiceStore) UpdatePerson(ctx context.Context, person domain.Person) (domain.Person, error) {
+	if err := person.Validate(); err != nil {
+		return domain.Person{}, err
+	}
+	person.UpdatedAt = now()
+	updated, err := s.PersonRepo.UpdatePerson(ctx, person)
+	if err != nil {
+		return domain.Person{}, err
+	}
+	return *updated, nil
+}
+
+// UpsertPerson creates person under its own id, or replaces the person
+// with that id as UpdatePerson does. It reports whether it created one.
+func (s *PersonServiceStore) UpsertPerson(ctx context.Context, person domain.Person) (domain.Person, bool, error) {
+	if err := person.Validate(); err != nil {
+		return domain.Person{}, false, err
+	}
+	person.CreatedAt = now()
+	person.UpdatedAt = person.CreatedAt
+	stored, created, err := s.PersonRepo.UpsertPerson(ctx, person)
+	if err != nil {
+		return domain.Person{}, false, err
+	}
+	return *stored, created, nil
+}
+
+// DeletePerson deletes a person, only at version unless it is 0. The person
+// can be restored until it is purged.
+func (s *PersonServiceStore) DeletePerson(ctx context.Context, id uuid.UUID, version int64) error {
+	return s.PersonRepo.DeletePerson(ctx, id, version, now())
 }
 
-func (s *PersonServiceStore) DeletePerson(ctx context.Context, id uuid.UUID) error {
-	return s.PersonRepo.DeletePerson(ctx, id)
+// now returns the current time at the microsecond precision Postgres
+// stores, so that persons compare the same before and after a round trip.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
 }
@@ -2,6 +2,9 @@ package person_service
 
 import (
 	"context"
+	"io"
+	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
@@ -9,8 +12,20 @@ import (
 
 type PersonServiceAbstrcatImpl interface {
 	CreatePerson(ctx context.Context, person domain.Person) error
-	GetAllPersons(ctx context.Context, limit, offset int) ([]domain.Person, error)
+	GetAllPersons(ctx context.Context, query domain.PersonQuery) ([]domain.Person, error)
+	ListPersons(ctx context.Context, query domain.PersonQuery, cursor string) (PersonPage, error)
+	CountPersons(ctx context.Context, filter domain.PersonFilter) (int, error)
 	GetPerson(ctx context.Context, id uuid.UUID) (domain.Person, error)
-	UpdatePerson(ctx context.Context, person domain.Person) error
-	DeletePerson(ctx context.Context, id uuid.UUID) error
+	UpdatePerson(ctx context.Context, person domain.Person) (domain.Person, error)
+	UpsertPerson(ctx context.Context, person domain.Person) (domain.Person, bool, error)
+	PatchPerson(ctx context.Context, id uuid.UUID, version int64, patchType PatchType, patch []byte) (domain.Person, error)
+	DeletePerson(ctx context.Context, id uuid.UUID, version int64) error
+	CreatePersons(ctx context.Context, persons []domain.Person, atomic bool) ([]domain.BatchResult, error)
+	UpdatePersons(ctx context.Context, persons []domain.Person, atomic bool) ([]domain.BatchResult, error)
+	DeletePersons(ctx context.Context, persons []domain.Person, atomic bool) ([]domain.BatchResult, error)
+	ExportPersons(ctx context.Context, query domain.PersonQuery, fn func(domain.Person) error) error
+	ImportPersons(ctx context.Context, r io.Reader, format PersonFormat) (ImportReport, error)
+	RestorePerson(ctx context.Context, id uuid.UUID) (domain.Person, error)
+	PurgeDeletedPersons(ctx context.Context, retention time.Duration) (int64, error)
+	RunPurger(ctx context.Context, interval, retention time.Duration, logger *slog.Logger)
 }
@@ -0,0 +1,311 @@
+package person_service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// PersonFormat is the media type of a person export or import.
+type PersonFormat string
+
+const (
+	// CSV has a header row naming the columns id, name, age and hobbies,
+	// with the hobbies of a person joined by HobbySeparator.
+	CSV PersonFormat = "text/csv"
+	// NDJSON has one JSON person per line.
+	NDJSON PersonFormat = "application/x-ndjson"
+)
+
+// HobbySeparator separates the hobbies in the hobbies column of a CSV.
+const HobbySeparator = ";"
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported person format")
+	ErrInvalidCSVHeader  = domain.NewError(domain.ErrInvalidInput, "invalid CSV header")
+)
+
+var csvColumns = []string{"id", "name", "age", "hobbies"}
+
+// personRecord is the JSON form of a person in NDJSON. Its members match
+// PersonResponse, so an export can be imported again.
+type personRecord struct {
+	Id      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	Age     int       `json:"age"`
+	Hobbies []string  `json:"hobbies"`
+}
+
+// PersonWriter writes persons in a PersonFormat.
+type PersonWriter interface {
+	Write(person domain.Person) error
+	// Flush writes any buffered persons to the underlying writer.
+	Flush() error
+}
+
+// NewPersonWriter returns a writer of persons in format to w.
+func NewPersonWriter(w io.Writer, format PersonFormat) (PersonWriter, error) {
+	switch format {
+	case CSV:
+		return &csvPersonWriter{w: csv.NewWriter(w)}, nil
+	case NDJSON:
+		buf := bufio.NewWriter(w)
+		return &ndjsonPersonWriter{buf: buf, enc: json.NewEncoder(buf)}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+type csvPersonWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func (w *csvPersonWriter) Write(person domain.Person) error {
+	if !w.wroteHeader {
+		if err := w.w.Write(csvColumns); err != nil {
+			return err
+		}
+		w.wroteHeader = true
+	}
+	return w.w.Write([]string{
+		person.Id.String(),
+		person.Name,
+		strconv.Itoa(person.Age),
+		strings.Join(person.Hobbies, HobbySeparator),
+	})
+}
+
+func (w *csvPersonWriter) Flush() error {
+	// An empty export still names its columns.
+	if !w.wroteHeader {
+		if err := w.w.Write(csvColumns); err != nil {
+			return err
+		}
+		w.wroteHeader = true
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type ndjsonPersonWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func (w *ndjsonPersonWriter) Write(person domain.Person) error {
+	hobbies := person.Hobbies
+	if hobbies == nil {
+		hobbies = []string{}
+	}
+	return w.enc.Encode(personRecord{
+		Id:      person.Id,
+		Name:    person.Name,
+		Age:     person.Age,
+		Hobbies: hobbies,
+	})
+}
+
+func (w *ndjsonPersonWriter) Flush() error {
+	return w.buf.Flush()
+}
+
+// PersonRow is one row read by a PersonReader. Row counts the data rows
+// of a CSV and the lines of NDJSON from 1. Err tells why the row could not
+// be read as a person; the rows after it can still be read.
+type PersonRow struct {
+	Row    int
+	Person domain.Person
+	Err    error
+}
+
+// PersonReader reads persons in a PersonFormat.
+type PersonReader interface {
+	// Read returns the next row, or io.EOF after the last one. Any other
+	// error means the rest of the input cannot be read.
+	Read() (PersonRow, error)
+}
+
+// maxNDJSONLine bounds the length of a line of NDJSON.
+const maxNDJSONLine = 1 << 20
+
+// NewPersonReader returns a reader of persons in format from r. A CSV
+// must start with a header row.
+func NewPersonReader(r io.Reader, format PersonFormat) (PersonReader, error) {
+	switch format {
+	case CSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+		return &csvPersonReader{r: cr}, nil
+	case NDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+		return &ndjsonPersonReader{scanner: scanner}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+type csvPersonReader struct {
+	r       *csv.Reader
+	columns []string
+	row     int
+}
+
+func (r *csvPersonReader) Read() (PersonRow, error) {
+	if r.columns == nil {
+		if err := r.readHeader(); err != nil {
+			return PersonRow{}, err
+		}
+	}
+
+	record, err := r.r.Read()
+	if err == io.EOF {
+		return PersonRow{}, io.EOF
+	}
+	r.row++
+	row := PersonRow{Row: r.row}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		row.Err = parseErr.Err
+		return row, nil
+	}
+	if err != nil {
+		return PersonRow{}, err
+	}
+	if len(record) != len(r.columns) {
+		row.Err = fmt.Errorf("has %d fields, want %d", len(record), len(r.columns))
+		return row, nil
+	}
+
+	for i, column := range r.columns {
+		value := strings.TrimSpace(record[i])
+		switch column {
+		case "id":
+			if value == "" {
+				continue
+			}
+			row.Person.Id, err = uuid.Parse(value)
+			if err != nil {
+				row.Err = fmt.Errorf("invalid id %q", value)
+				return row, nil
+			}
+		case "name":
+			row.Person.Name = value
+		case "age":
+			row.Person.Age, err = strconv.Atoi(value)
+			if err != nil {
+				row.Err = fmt.Errorf("age %q is not an integer", value)
+				return row, nil
+			}
+		case "hobbies":
+			row.Person.Hobbies = splitHobbies(value)
+		}
+	}
+	if row.Person.Hobbies == nil {
+		row.Person.Hobbies = []string{}
+	}
+	return row, nil
+}
+
+// readHeader reads the column names. Only name and age are required.
+func (r *csvPersonReader) readHeader() error {
+	header, err := r.r.Read()
+	if err == io.EOF {
+		return fmt.Errorf("%w: missing header row", ErrInvalidCSVHeader)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidCSVHeader, err)
+	}
+
+	seen := make(map[string]bool, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if i == 0 {
+			// Spreadsheets often save CSV with a byte order mark.
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		known := false
+		for _, column := range csvColumns {
+			known = known || name == column
+		}
+		if !known {
+			return fmt.Errorf("%w: unknown column %q", ErrInvalidCSVHeader, name)
+		}
+		if seen[name] {
+			return fmt.Errorf("%w: duplicate column %q", ErrInvalidCSVHeader, name)
+		}
+		seen[name] = true
+		header[i] = name
+	}
+	for _, required := range []string{"name", "age"} {
+		if !seen[required] {
+			return fmt.Errorf("%w: missing column %q", ErrInvalidCSVHeader, required)
+		}
+	}
+	r.columns = header
+	return nil
+}
+
+// splitHobbies splits a hobbies column, dropping empty hobbies.
+func splitHobbies(value string) []string {
+	hobbies := []string{}
+	for _, hobby := range strings.Split(value, HobbySeparator) {
+		if hobby = strings.TrimSpace(hobby); hobby != "" {
+			hobbies = append(hobbies, hobby)
+		}
+	}
+	return hobbies
+}
+
+type ndjsonPersonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (r *ndjsonPersonReader) Read() (PersonRow, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		row := PersonRow{Row: r.line}
+		var record personRecord
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&record); err != nil {
+			row.Err = fmt.Errorf("invalid JSON: %w", err)
+			return row, nil
+		}
+		if dec.More() {
+			row.Err = errors.New("invalid JSON: more than one value on the line")
+			return row, nil
+		}
+		if record.Hobbies == nil {
+			record.Hobbies = []string{}
+		}
+		row.Person = domain.Person{
+			Id:      record.Id,
+			Name:    record.Name,
+			Age:     record.Age,
+			Hobbies: record.Hobbies,
+		}
+		return row, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return PersonRow{}, err
+	}
+	return PersonRow{}, io.EOF
+}
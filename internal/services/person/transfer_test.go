@@ -0,0 +1,215 @@
+package person_service
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// wantRow is a row a PersonReader should return. A row with wantErr set
+// must have an Err and is not compared otherwise.
+type wantRow struct {
+	row     int
+	person  domain.Person
+	wantErr bool
+}
+
+// readRows reads every row of input in format, returning the error that
+// ended the input unless it is io.EOF.
+func readRows(t *testing.T, input string, format PersonFormat) ([]PersonRow, error) {
+	t.Helper()
+	reader, err := NewPersonReader(strings.NewReader(input), format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []PersonRow
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return rows, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+func checkRows(t *testing.T, got []PersonRow, want []wantRow) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("read %d rows, want %d: %+v", len(got), len(want), got)
+	}
+	for i, row := range got {
+		if row.Row != want[i].row {
+			t.Errorf("row %d is numbered %d, want %d", i, row.Row, want[i].row)
+		}
+		if want[i].wantErr {
+			if row.Err == nil {
+				t.Errorf("row %d = %+v, want an error", want[i].row, row.Person)
+			}
+			continue
+		}
+		if row.Err != nil {
+			t.Errorf("row %d: %v", want[i].row, row.Err)
+		} else if !reflect.DeepEqual(row.Person, want[i].person) {
+			t.Errorf("row %d = %+v, want %+v", want[i].row, row.Person, want[i].person)
+		}
+	}
+}
+
+var transferID = uuid.MustParse("5f0c8d0e-2b7a-4d55-8f3e-1c2a9b4e6d70")
+
+func TestCSVPersonReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []wantRow
+		wantErr error
+	}{
+		{
+			name:  "all columns",
+			input: "id,name,age,hobbies\n" + transferID.String() + ",Al,3,chess; go ;\n,Bo,4,\n",
+			want: []wantRow{
+				{row: 1, person: domain.Person{Id: transferID, Name: "Al", Age: 3, Hobbies: []string{"chess", "go"}}},
+				{row: 2, person: domain.Person{Name: "Bo", Age: 4, Hobbies: []string{}}},
+			},
+		},
+		{
+			name:  "reordered header with byte order mark",
+			input: "\ufeffAge, Name\n3,Al\n",
+			want:  []wantRow{{row: 1, person: domain.Person{Name: "Al", Age: 3, Hobbies: []string{}}}},
+		},
+		{
+			name:  "quoted fields",
+			input: "name,age,hobbies\n\"O'Brien, Al\",3,\"a;b\"\n",
+			want:  []wantRow{{row: 1, person: domain.Person{Name: "O'Brien, Al", Age: 3, Hobbies: []string{"a", "b"}}}},
+		},
+		{
+			name:  "bad rows are reported and skipped",
+			input: "name,age,id\nAl,x,\nBo,4,not-a-uuid\nCy,5\nDee,6,\n",
+			want: []wantRow{
+				{row: 1, wantErr: true},
+				{row: 2, wantErr: true},
+				{row: 3, wantErr: true},
+				{row: 4, person: domain.Person{Name: "Dee", Age: 6, Hobbies: []string{}}},
+			},
+		},
+		{
+			name:  "malformed quoting",
+			input: "name,age\n\"Al,3\n",
+			want:  []wantRow{{row: 1, wantErr: true}},
+		},
+		{name: "header only", input: "name,age\n"},
+		{name: "empty", input: "", wantErr: ErrInvalidCSVHeader},
+		{name: "unknown column", input: "name,age,email\n", wantErr: ErrInvalidCSVHeader},
+		{name: "duplicate column", input: "name,age,name\n", wantErr: ErrInvalidCSVHeader},
+		{name: "missing age column", input: "id,name\n", wantErr: ErrInvalidCSVHeader},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := readRows(t, tt.input, CSV)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			checkRows(t, rows, tt.want)
+		})
+	}
+}
+
+func TestNDJSONPersonReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []wantRow
+		wantErr error
+	}{
+		{
+			name:  "persons",
+			input: `{"id":"` + transferID.String() + `","name":"Al","age":3,"hobbies":["chess"]}` + "\n" + `{"name":"Bo","age":4}`,
+			want: []wantRow{
+				{row: 1, person: domain.Person{Id: transferID, Name: "Al", Age: 3, Hobbies: []string{"chess"}}},
+				{row: 2, person: domain.Person{Name: "Bo", Age: 4, Hobbies: []string{}}},
+			},
+		},
+		{
+			name:  "blank lines count but are skipped",
+			input: "\n  \r\n{\"name\":\"Al\",\"age\":3}\r\n",
+			want:  []wantRow{{row: 3, person: domain.Person{Name: "Al", Age: 3, Hobbies: []string{}}}},
+		},
+		{
+			name:  "bad lines are reported and skipped",
+			input: "{\"name\":\"Al\",\n{\"name\":\"Bo\",\"email\":\"x\"}\n{\"name\":\"Cy\"} {}\n{\"age\":\"4\"}\n{\"name\":\"Dee\",\"age\":6}\n",
+			want: []wantRow{
+				{row: 1, wantErr: true},
+				{row: 2, wantErr: true},
+				{row: 3, wantErr: true},
+				{row: 4, wantErr: true},
+				{row: 5, person: domain.Person{Name: "Dee", Age: 6, Hobbies: []string{}}},
+			},
+		},
+		{name: "empty", input: ""},
+		{name: "line too long", input: `{"name":"` + strings.Repeat("a", maxNDJSONLine) + `"}`, wantErr: bufio.ErrTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := readRows(t, tt.input, NDJSON)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			checkRows(t, rows, tt.want)
+		})
+	}
+}
+
+// TestPersonWriterRoundTrip checks that an export reads back as the same
+// persons.
+func TestPersonWriterRoundTrip(t *testing.T) {
+	persons := []domain.Person{
+		{Id: transferID, Name: "O'Brien, Al", Age: 3, Hobbies: []string{"chess", "go"}},
+		{Id: uuid.New(), Name: "Bo", Age: 0, Hobbies: []string{}},
+	}
+	for _, format := range []PersonFormat{CSV, NDJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := NewPersonWriter(&buf, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, person := range persons {
+				if err := writer.Write(person); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := writer.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			rows, err := readRows(t, buf.String(), format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := make([]wantRow, len(persons))
+			for i, person := range persons {
+				want[i] = wantRow{row: i + 1, person: person}
+			}
+			checkRows(t, rows, want)
+		})
+	}
+}
+
+func TestPersonFormatUnsupported(t *testing.T) {
+	if _, err := NewPersonReader(strings.NewReader(""), "text/plain"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("NewPersonReader error = %v, want ErrUnsupportedFormat", err)
+	}
+	if _, err := NewPersonWriter(io.Discard, "text/plain"); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("NewPersonWriter error = %v, want ErrUnsupportedFormat", err)
+	}
+}
@@ -0,0 +1,294 @@
+package util
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ErrNotEncodable is returned by an Encoder for values that have no form
+// in its media type, such as a message without data in CSV.
+var ErrNotEncodable = errors.New("value cannot be encoded in this media type")
+
+// Encoder writes response bodies in a media type.
+type Encoder interface {
+	// MediaTypes lists the media types the encoder is negotiated for. The
+	// first is sent as the Content-Type.
+	MediaTypes() []string
+	Encode(w io.Writer, v interface{}) error
+}
+
+// Decoder reads request bodies in a media type.
+type Decoder interface {
+	// MediaTypes lists the Content-Types the decoder reads.
+	MediaTypes() []string
+	Decode(r io.Reader, v interface{}) error
+}
+
+// encoders are the response encoders in order of preference for clients
+// that accept several equally. JSON comes first, so it is the default.
+var encoders = []Encoder{JSONCodec{}, XMLCodec{}, MsgpackCodec{}, CSVEncoder{}}
+
+// ListEncoder is an Encoder with no form for most responses other than
+// lists, such as CSV. Only routes wrapped in NegotiateList offer it.
+type ListEncoder interface {
+	Encoder
+	ListsOnly()
+}
+
+// decoders are the request body decoders.
+var decoders = []Decoder{JSONCodec{}, XMLCodec{}, MsgpackCodec{}}
+
+// RegisterEncoder adds an encoder, replacing any registered for its first
+// media type. It must be called before the server starts.
+func RegisterEncoder(enc Encoder) {
+	for i, registered := range encoders {
+		if registered.MediaTypes()[0] == enc.MediaTypes()[0] {
+			encoders[i] = enc
+			return
+		}
+	}
+	encoders = append(encoders, enc)
+}
+
+// RegisterDecoder adds a decoder, replacing any registered for its first
+// media type. It must be called before the server starts.
+func RegisterDecoder(dec Decoder) {
+	for i, registered := range decoders {
+		if registered.MediaTypes()[0] == dec.MediaTypes()[0] {
+			decoders[i] = dec
+			return
+		}
+	}
+	decoders = append(decoders, dec)
+}
+
+// JSONCodec encodes and decodes JSON. It decodes strictly: a body must be
+// a single JSON value with no members v lacks.
+type JSONCodec struct{}
+
+func (JSONCodec) MediaTypes() []string { return []string{"application/json"} }
+
+func (JSONCodec) Encode(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func (JSONCodec) Decode(r io.Reader, v interface{}) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return decodeStrictJSON(data, v)
+}
+
+// XMLCodec encodes and decodes XML. encoding/xml has no strict mode, so
+// unknown elements are ignored.
+type XMLCodec struct{}
+
+func (XMLCodec) MediaTypes() []string { return []string{"application/xml", "text/xml"} }
+
+func (XMLCodec) Encode(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+}
+
+func (XMLCodec) Decode(r io.Reader, v interface{}) error {
+	return xml.NewDecoder(r).Decode(v)
+}
+
+// MsgpackCodec encodes and decodes MessagePack. Maps are keyed by the json
+// tags of struct fields, so documents have the same shape as in JSON, but
+// values with a binary form such as UUIDs are sent as bin.
+type MsgpackCodec struct{}
+
+func (MsgpackCodec) MediaTypes() []string {
+	return []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}
+}
+
+func (MsgpackCodec) Encode(w io.Writer, v interface{}) error {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	return enc.Encode(v)
+}
+
+func (MsgpackCodec) Decode(r io.Reader, v interface{}) error {
+	dec := msgpack.NewDecoder(r)
+	dec.SetCustomStructTag("json")
+	dec.DisallowUnknownFields(true)
+	return dec.Decode(v)
+}
+
+// CSVEncoder encodes the data of a response as CSV: a header row of the
+// json names of its fields, then a row per item of a list, or a single row
+// for an object. Lists of strings are joined by CSVListSeparator.
+type CSVEncoder struct{}
+
+// CSVListSeparator joins the items of a list in a CSV field.
+const CSVListSeparator = ";"
+
+func (CSVEncoder) MediaTypes() []string { return []string{"text/csv"} }
+
+func (CSVEncoder) ListsOnly() {}
+
+func (CSVEncoder) Encode(w io.Writer, v interface{}) error {
+	rows := reflect.ValueOf(responseData(v))
+	for rows.Kind() == reflect.Pointer || rows.Kind() == reflect.Interface {
+		rows = rows.Elem()
+	}
+
+	var items []reflect.Value
+	switch rows.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rows.Len(); i++ {
+			items = append(items, rows.Index(i))
+		}
+	case reflect.Struct:
+		items = []reflect.Value{rows}
+	default:
+		return ErrNotEncodable
+	}
+
+	elem := rows.Type()
+	if rows.Kind() != reflect.Struct {
+		elem = elem.Elem()
+	}
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return ErrNotEncodable
+	}
+	columns := csvColumns(elem)
+
+	cw := csv.NewWriter(w)
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, item := range items {
+		for item.Kind() == reflect.Pointer {
+			item = item.Elem()
+		}
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			field, err := item.FieldByIndexErr(column.index)
+			if err != nil {
+				// A nil embedded pointer leaves the field empty.
+				continue
+			}
+			if record[i], err = csvField(field); err != nil {
+				return err
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// responseData unwraps the data of a response envelope.
+func responseData(v interface{}) interface{} {
+	switch response := v.(type) {
+	case APIResponse:
+		return response.Data
+	case ListResponse:
+		return response.Data
+	}
+	return v
+}
+
+type csvColumn struct {
+	name  string
+	index []int
+}
+
+// csvColumns lists the exported fields of t under their json names,
+// flattening embedded structs as encoding/json does.
+func csvColumns(t reflect.Type) []csvColumn {
+	var columns []csvColumn
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && derefType(field.Type).Kind() == reflect.Struct {
+			// Its fields are visited on their own.
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		columns = append(columns, csvColumn{name: name, index: field.Index})
+	}
+	return columns
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// csvField renders one field value.
+func csvField(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return string(text), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	case reflect.Slice, reflect.Array:
+		items := make([]string, v.Len())
+		for i := range items {
+			item, err := csvField(v.Index(i))
+			if err != nil {
+				return "", err
+			}
+			items[i] = item
+		}
+		return strings.Join(items, CSVListSeparator), nil
+	default:
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(v.Interface()); err != nil {
+			return "", fmt.Errorf("encoding %s field: %w", v.Type(), err)
+		}
+		return strings.TrimSpace(buf.String()), nil
+	}
+}
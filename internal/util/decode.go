@@ -0,0 +1,200 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxRequestBytes bounds the request bodies DecodeRequest and DecodeJSON
+// read.
+const MaxRequestBytes = 1 << 20
+
+// ErrUnsupportedMediaType is the cause of the 415 RequestError of a
+// Content-Type no decoder reads.
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// RequestError is a request body that was rejected before it was decoded,
+// with the status to answer it with: 400 for a malformed body, 413 for one
+// that is too large and 415 for an unsupported Content-Type.
+type RequestError struct {
+	Status int
+	Err    error
+}
+
+func (e *RequestError) Error() string { return e.Err.Error() }
+
+func (e *RequestError) Unwrap() error { return e.Err }
+
+// SyntaxError is a JSON body that does not decode, at the 1-based Line and
+// Column of the offending input. Both are 0 when the position is unknown.
+type SyntaxError struct {
+	Message string
+	Line    int
+	Column  int
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Line == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s at line %d, column %d", e.Message, e.Line, e.Column)
+}
+
+// DecodeRequest decodes the body of r into v with the decoder for its
+// Content-Type and validates it. Bodies are limited to MaxRequestBytes.
+// Errors reading the body are RequestErrors; an invalid v gets the errors
+// of Validate.
+func DecodeRequest(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	return decodeBody(w, r, v, MaxRequestBytes, decoders)
+}
+
+// DecodeJSON is DecodeRequest for endpoints that only read JSON.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	return DecodeJSONLimit(w, r, v, MaxRequestBytes)
+}
+
+// DecodeJSONLimit is DecodeJSON with a body limit of maxBytes.
+func DecodeJSONLimit(w http.ResponseWriter, r *http.Request, v interface{}, maxBytes int64) error {
+	return decodeBody(w, r, v, maxBytes, []Decoder{JSONCodec{}})
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}, maxBytes int64, accepted []Decoder) error {
+	dec, ok := requestDecoder(r, accepted)
+	if !ok {
+		return &RequestError{
+			Status: http.StatusUnsupportedMediaType,
+			Err:    fmt.Errorf("%w: Content-Type must be one of %s", ErrUnsupportedMediaType, mediaTypesOf(accepted)),
+		}
+	}
+
+	if err := dec.Decode(http.MaxBytesReader(w, r.Body, maxBytes), v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return &RequestError{
+				Status: http.StatusRequestEntityTooLarge,
+				Err:    fmt.Errorf("request body exceeds %d bytes", maxBytes),
+			}
+		}
+		return &RequestError{Status: http.StatusBadRequest, Err: err}
+	}
+	return Validate(v)
+}
+
+// requestDecoder picks the decoder of accepted for the Content-Type of r.
+func requestDecoder(r *http.Request, accepted []Decoder) (Decoder, bool) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, false
+	}
+	for _, dec := range accepted {
+		if slices.Contains(dec.MediaTypes(), mediaType) {
+			return dec, true
+		}
+	}
+	return nil, false
+}
+
+func mediaTypesOf(decoders []Decoder) string {
+	var mediaTypes []string
+	for _, dec := range decoders {
+		mediaTypes = append(mediaTypes, dec.MediaTypes()...)
+	}
+	return strings.Join(mediaTypes, ", ")
+}
+
+// AcceptedRequestTypes lists the Content-Types DecodeRequest reads.
+func AcceptedRequestTypes() string {
+	return mediaTypesOf(decoders)
+}
+
+// decodeStrictJSON decodes data, a single JSON value whose object members
+// must all be fields of v, into v. Its errors are SyntaxErrors placing the
+// problem in data.
+func decodeStrictJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(v)
+	if err == nil {
+		if trailing := bytes.TrimLeft(data[dec.InputOffset():], " \t\r\n"); len(trailing) > 0 {
+			return syntaxErrorAt(data, int64(len(data)-len(trailing)), "request body must contain a single JSON value")
+		}
+		return nil
+	}
+
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.Is(err, io.EOF):
+		return &SyntaxError{Message: "request body is empty"}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return syntaxErrorAt(data, int64(len(data)), "request body ends before its JSON value is complete")
+	case errors.As(err, &syntaxErr):
+		return syntaxErrorAt(data, syntaxErr.Offset-1, "malformed JSON: "+syntaxErr.Error())
+	case errors.As(err, &typeErr):
+		message := fmt.Sprintf("%s must be %s, not %s", jsonFieldName(typeErr.Field), jsonTypeName(typeErr.Type), typeErr.Value)
+		return syntaxErrorAt(data, typeErr.Offset-1, message)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// The error has no offset, but the decoder stopped just past the
+		// member, so its name is the last occurrence before that.
+		name := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		offset := bytes.LastIndex(data[:dec.InputOffset()], []byte(name))
+		return syntaxErrorAt(data, int64(offset), "unknown field "+name)
+	default:
+		// Errors such as those of MaxBytesReader are not about the body's
+		// content.
+		return err
+	}
+}
+
+// syntaxErrorAt is a SyntaxError at the byte offset of data.
+func syntaxErrorAt(data []byte, offset int64, message string) *SyntaxError {
+	offset = max(0, min(offset, int64(len(data))))
+	before := data[:offset]
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	return &SyntaxError{
+		Message: message,
+		Line:    bytes.Count(before, []byte{'\n'}) + 1,
+		Column:  utf8.RuneCount(before[lineStart:]) + 1,
+	}
+}
+
+// jsonFieldName names a field of a json.UnmarshalTypeError, "hobbies.0"
+// for instance, for messages.
+func jsonFieldName(field string) string {
+	if field == "" {
+		return "request body"
+	}
+	return fmt.Sprintf("%q", field)
+}
+
+// jsonTypeName is the kind of JSON value that decodes into t.
+func jsonTypeName(t reflect.Type) string {
+	switch derefType(t).Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	default:
+		return "a " + t.String()
+	}
+}
@@ -0,0 +1,177 @@
+package util
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+type decodeTarget struct {
+	Name    string   `json:"name" xml:"name" validate:"required,max=10"`
+	Age     int      `json:"age" xml:"age" validate:"gte=0"`
+	Hobbies []string `json:"hobbies" xml:"hobbies>hobby"`
+}
+
+func newDecodeRequest(contentType, body string) (*httptest.ResponseRecorder, *http.Request) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	return httptest.NewRecorder(), r
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        decodeTarget
+		wantStatus  int
+		// wantMessage, wantLine and wantColumn describe the SyntaxError of
+		// a malformed body. Values of the wrong type are placed at their
+		// last character.
+		wantMessage string
+		wantLine    int
+		wantColumn  int
+	}{
+		{name: "valid", contentType: "application/json", body: `{"name":"Al","age":3,"hobbies":["chess"]}`, want: decodeTarget{Name: "Al", Age: 3, Hobbies: []string{"chess"}}},
+		{name: "charset parameter", contentType: "application/json; charset=utf-8", body: `{"name":"Al"}`, want: decodeTarget{Name: "Al"}},
+		{name: "trailing whitespace", contentType: "application/json", body: "{\"name\":\"Al\"}\n\t ", want: decodeTarget{Name: "Al"}},
+		{name: "no content type", body: `{"name":"Al"}`, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "other content type", contentType: "text/plain", body: `{"name":"Al"}`, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "xml to a JSON endpoint", contentType: "application/xml", body: `<p><name>Al</name></p>`, wantStatus: http.StatusUnsupportedMediaType},
+		{
+			name: "unknown field", contentType: "application/json", body: `{"name":"Al","nick":"x"}`,
+			wantStatus: http.StatusBadRequest, wantMessage: `unknown field "nick"`, wantLine: 1, wantColumn: 14,
+		},
+		{
+			name: "unknown field named like a value", contentType: "application/json", body: "{\"name\":\"nick\",\n \"nick\":1}",
+			wantStatus: http.StatusBadRequest, wantMessage: `unknown field "nick"`, wantLine: 2, wantColumn: 2,
+		},
+		{
+			name: "wrong type", contentType: "application/json", body: "{\n  \"name\": \"Al\",\n  \"age\": \"3\"\n}",
+			wantStatus: http.StatusBadRequest, wantMessage: `"age" must be a number, not string`, wantLine: 3, wantColumn: 12,
+		},
+		{
+			name: "wrong item type", contentType: "application/json", body: `{"name":"Al","hobbies":[1]}`,
+			wantStatus: http.StatusBadRequest, wantMessage: `"hobbies.0" must be a string, not number`, wantLine: 1, wantColumn: 25,
+		},
+		{
+			name: "second value", contentType: "application/json", body: `{"name":"Al"} {"name":"Bo"}`,
+			wantStatus: http.StatusBadRequest, wantMessage: "request body must contain a single JSON value", wantLine: 1, wantColumn: 15,
+		},
+		{
+			name: "malformed", contentType: "application/json", body: "{\"name\":\"Al\",\n,}",
+			wantStatus: http.StatusBadRequest, wantLine: 2, wantColumn: 1,
+		},
+		{
+			name: "truncated", contentType: "application/json", body: `{"name":"Al"`,
+			wantStatus: http.StatusBadRequest, wantMessage: "request body ends before its JSON value is complete", wantLine: 1, wantColumn: 13,
+		},
+		{name: "empty", contentType: "application/json", body: "", wantStatus: http.StatusBadRequest, wantMessage: "request body is empty"},
+		{name: "invalid", contentType: "application/json", body: `{"name":"","age":-1}`, wantStatus: http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, r := newDecodeRequest(tt.contentType, tt.body)
+			var got decodeTarget
+			err := DecodeJSON(w, r, &got)
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("DecodeJSON: %v", err)
+				}
+				if got.Name != tt.want.Name || got.Age != tt.want.Age || strings.Join(got.Hobbies, ",") != strings.Join(tt.want.Hobbies, ",") {
+					t.Errorf("DecodeJSON decoded %+v, want %+v", got, tt.want)
+				}
+				return
+			}
+
+			if status := ErrorStatus(err); status != tt.wantStatus {
+				t.Fatalf("DecodeJSON error %v has status %d, want %d", err, status, tt.wantStatus)
+			}
+			if tt.wantLine == 0 && tt.wantMessage == "" {
+				return
+			}
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("DecodeJSON error %v is not a SyntaxError", err)
+			}
+			if tt.wantMessage != "" && syntaxErr.Message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", syntaxErr.Message, tt.wantMessage)
+			}
+			if syntaxErr.Line != tt.wantLine || syntaxErr.Column != tt.wantColumn {
+				t.Errorf("position = line %d, column %d; want line %d, column %d", syntaxErr.Line, syntaxErr.Column, tt.wantLine, tt.wantColumn)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONValidationErrors(t *testing.T) {
+	w, r := newDecodeRequest("application/json", `{"name":"","age":-1}`)
+	err := DecodeJSON(w, r, &decodeTarget{})
+
+	var fieldErrs domain.FieldErrors
+	if !errors.As(err, &fieldErrs) {
+		t.Fatalf("DecodeJSON error %v is not FieldErrors", err)
+	}
+	var fields []string
+	for _, fieldErr := range fieldErrs {
+		fields = append(fields, fieldErr.Field)
+	}
+	if got := strings.Join(fields, ","); got != "name,age" {
+		t.Errorf("invalid fields = %s, want name,age", got)
+	}
+}
+
+func TestDecodeJSONLimit(t *testing.T) {
+	w, r := newDecodeRequest("application/json", `{"name":"Al","hobbies":["chess","go"]}`)
+	err := DecodeJSONLimit(w, r, &decodeTarget{}, 16)
+	if status := ErrorStatus(err); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("DecodeJSONLimit error %v has status %d, want 413", err, status)
+	}
+
+	w, r = newDecodeRequest("application/json", `{"name":"Al"}`)
+	if err := DecodeJSONLimit(w, r, &decodeTarget{}, 16); err != nil {
+		t.Errorf("DecodeJSONLimit of a body under the limit: %v", err)
+	}
+}
+
+func TestDecodeRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{name: "json", contentType: "application/json", body: `{"name":"Al","age":3}`},
+		{name: "xml", contentType: "application/xml", body: `<person><name>Al</name><age>3</age></person>`},
+		{name: "text xml", contentType: "text/xml", body: `<person><name>Al</name><age>3</age></person>`},
+		{name: "msgpack", contentType: "application/msgpack", body: "\x82\xa4name\xa2Al\xa3age\x03"},
+		{name: "msgpack unknown field", contentType: "application/msgpack", body: "\x82\xa4name\xa2Al\xa4nick\x03", wantStatus: http.StatusBadRequest},
+		{name: "csv", contentType: "text/csv", body: "name,age\nAl,3\n", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "malformed content type", contentType: "application/", body: `{}`, wantStatus: http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, r := newDecodeRequest(tt.contentType, tt.body)
+			var got decodeTarget
+			err := DecodeRequest(w, r, &got)
+			if tt.wantStatus != 0 {
+				if status := ErrorStatus(err); status != tt.wantStatus {
+					t.Errorf("DecodeRequest error %v has status %d, want %d", err, status, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeRequest: %v", err)
+			}
+			if got.Name != "Al" || got.Age != 3 {
+				t.Errorf("DecodeRequest decoded %+v, want Al aged 3", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package util
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// ErrorStatus is the HTTP status of err by its domain error kind, or that
+// of a RequestError, and 500 for errors of no kind.
+func ErrorStatus(err error) int {
+	var requestErr *RequestError
+	switch {
+	case errors.As(err, &requestErr):
+		return requestErr.Status
+	case errors.Is(err, domain.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, domain.ErrValidation):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// WriteDomainError writes err as a problem with the status of its kind,
+// pointing at the fields it is about. Errors of no kind may carry internal
+// details, so their problem says message instead.
+func WriteDomainError(w http.ResponseWriter, err error, message string) {
+	status := ErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		WriteErrorResponse(w, status, message)
+		return
+	}
+	WriteProblem(w, Problem{Status: status, Detail: err.Error(), Errors: FieldErrors(err, "")})
+}
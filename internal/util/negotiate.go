@@ -0,0 +1,168 @@
+package util
+
+import (
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// negotiatedWriter carries the encoders Negotiate chose for a request to
+// the response writers.
+type negotiatedWriter struct {
+	http.ResponseWriter
+	encoders []Encoder
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *negotiatedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Negotiate picks the encoders of the responses to r from its Accept
+// header, in the order the client prefers them, and answers 406 when it
+// accepts none, before next runs. Handlers below it write through the
+// Write*Response helpers as usual; the first chosen encoder that can encode
+// a response is used. List encoders are not offered; see NegotiateList.
+func Negotiate(next http.Handler) http.Handler {
+	return negotiate(false, next)
+}
+
+// NegotiateList is Negotiate for routes answering with a list, which may
+// also be sent in the media types of list encoders such as CSV.
+func NegotiateList(next http.Handler) http.Handler {
+	return negotiate(true, next)
+}
+
+func negotiate(lists bool, next http.Handler) http.Handler {
+	offered := make([]Encoder, 0, len(encoders))
+	for _, enc := range encoders {
+		if _, listOnly := enc.(ListEncoder); !listOnly || lists {
+			offered = append(offered, enc)
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		accepted := acceptedEncoders(offered, r.Header.Get("Accept"))
+		if len(accepted) == 0 {
+			writeNotAcceptable(w, offered)
+			return
+		}
+		next.ServeHTTP(&negotiatedWriter{ResponseWriter: w, encoders: accepted}, r)
+	})
+}
+
+// acceptRange is one media range of an Accept header.
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// specificity ranks exact types over type/* over */*.
+func (a acceptRange) specificity() int {
+	switch {
+	case a.mediaType == "*/*":
+		return 0
+	case strings.HasSuffix(a.mediaType, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (a acceptRange) matches(mediaType string) bool {
+	switch a.specificity() {
+	case 0:
+		return true
+	case 1:
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(a.mediaType, "*"))
+	default:
+		return a.mediaType == mediaType
+	}
+}
+
+// parseAccept parses an Accept header, skipping malformed ranges.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+	return ranges
+}
+
+// acceptedEncoders returns the offered encoders acceptable under the
+// Accept header, most preferred first. Every encoder is acceptable when
+// the header is missing.
+func acceptedEncoders(offered []Encoder, header string) []Encoder {
+	if strings.TrimSpace(header) == "" {
+		return offered
+	}
+	ranges := parseAccept(header)
+
+	type candidate struct {
+		encoder Encoder
+		q       float64
+	}
+	var candidates []candidate
+	for _, encoder := range offered {
+		// The quality of a type is that of the most specific range
+		// matching it, over all of its aliases.
+		q, specificity := 0.0, -1
+		for _, mediaType := range encoder.MediaTypes() {
+			for _, accepted := range ranges {
+				if !accepted.matches(mediaType) {
+					continue
+				}
+				switch s := accepted.specificity(); {
+				case s > specificity:
+					q, specificity = accepted.q, s
+				case s == specificity:
+					q = max(q, accepted.q)
+				}
+			}
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{encoder: encoder, q: q})
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+		return 0
+	})
+
+	accepted := make([]Encoder, len(candidates))
+	for i, c := range candidates {
+		accepted[i] = c.encoder
+	}
+	return accepted
+}
+
+// supportedMediaTypes lists the first media type of every offered encoder.
+func supportedMediaTypes(offered []Encoder) string {
+	mediaTypes := make([]string, len(offered))
+	for i, encoder := range offered {
+		mediaTypes[i] = encoder.MediaTypes()[0]
+	}
+	return strings.Join(mediaTypes, ", ")
+}
+
+func writeNotAcceptable(w http.ResponseWriter, offered []Encoder) {
+	WriteErrorResponse(w, http.StatusNotAcceptable, "Not Acceptable: supported media types are "+supportedMediaTypes(offered))
+}
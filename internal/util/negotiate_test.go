@@ -0,0 +1,84 @@
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestAcceptedEncoders(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   []string
+	}{
+		{name: "no header", header: "", want: []string{"application/json", "application/xml", "application/msgpack", "text/csv"}},
+		{name: "any", header: "*/*", want: []string{"application/json", "application/xml", "application/msgpack", "text/csv"}},
+		{name: "exact", header: "application/xml", want: []string{"application/xml"}},
+		{name: "alias", header: "application/x-msgpack", want: []string{"application/msgpack"}},
+		{name: "type wildcard", header: "text/*", want: []string{"application/xml", "text/csv"}},
+		{name: "by quality", header: "application/json;q=0.5, text/csv", want: []string{"text/csv", "application/json"}},
+		{name: "specific range overrides wildcard", header: "*/*;q=0.1, application/json;q=0", want: []string{"application/xml", "application/msgpack", "text/csv"}},
+		{name: "best alias wins", header: "text/xml;q=0.3, application/xml;q=0.8, application/json;q=0.5", want: []string{"application/xml", "application/json"}},
+		{name: "parameters ignored", header: "application/json; charset=utf-8", want: []string{"application/json"}},
+		{name: "unsupported", header: "image/png"},
+		{name: "quality out of range", header: "application/json;q=2"},
+		{name: "malformed", header: "application/json;;;"},
+		{name: "malformed range skipped", header: "//, text/csv", want: []string{"text/csv"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, enc := range acceptedEncoders(encoders, tt.header) {
+				got = append(got, enc.MediaTypes()[0])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("acceptedEncoders(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name            string
+		negotiate       func(http.Handler) http.Handler
+		accept          string
+		data            interface{}
+		wantStatus      int
+		wantContentType string
+		wantRun         bool
+	}{
+		{name: "list as csv", negotiate: NegotiateList, accept: "text/csv", data: []struct{ A int }{{1}},
+			wantStatus: http.StatusOK, wantContentType: "text/csv", wantRun: true},
+		{name: "csv not offered", negotiate: Negotiate, accept: "text/csv",
+			wantStatus: http.StatusNotAcceptable, wantContentType: ProblemMediaType},
+		{name: "csv skipped for a wildcard", negotiate: Negotiate, accept: "text/*", data: []struct{ A int }{{1}},
+			wantStatus: http.StatusOK, wantContentType: "application/xml", wantRun: true},
+		{name: "unsupported", negotiate: NegotiateList, accept: "image/png",
+			wantStatus: http.StatusNotAcceptable, wantContentType: ProblemMediaType},
+		{name: "message falls back to json", negotiate: NegotiateList, accept: "text/csv",
+			wantStatus: http.StatusOK, wantContentType: "application/json", wantRun: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := false
+			handler := tt.negotiate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				run = true
+				WriteSuccessResponse(w, tt.data, "done")
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", tt.accept)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus || w.Header().Get("Content-Type") != tt.wantContentType {
+				t.Errorf("got %d %s, want %d %s", w.Code, w.Header().Get("Content-Type"), tt.wantStatus, tt.wantContentType)
+			}
+			if run != tt.wantRun {
+				t.Errorf("handler ran = %v, want %v", run, tt.wantRun)
+			}
+		})
+	}
+}
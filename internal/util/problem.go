@@ -0,0 +1,78 @@
+package util
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// ProblemMediaType is the Content-Type of error responses.
+const ProblemMediaType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details object, the body of every error
+// response. Instance is the request path and RequestID the ID RequestID
+// gave the request. Errors points at the offending fields of the request
+// body and Data carries a partial result, such as the report of an import.
+type Problem struct {
+	Type      string         `json:"type"`
+	Title     string         `json:"title"`
+	Status    int            `json:"status"`
+	Detail    string         `json:"detail,omitempty"`
+	Instance  string         `json:"instance,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+	Errors    []ProblemField `json:"errors,omitempty"`
+	Data      interface{}    `json:"data,omitempty"`
+}
+
+// ProblemField is an error about the field of the request body at the JSON
+// Pointer (RFC 6901) Pointer.
+type ProblemField struct {
+	Pointer string `json:"pointer"`
+	Detail  string `json:"detail"`
+}
+
+// WriteProblem writes p, filling in what it leaves unset from its status
+// and the request. Problems are always JSON, whatever the request
+// negotiated.
+func WriteProblem(w http.ResponseWriter, p Problem) {
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if request, ok := unwrapWriter[*requestWriter](w); ok {
+		if p.Instance == "" {
+			p.Instance = request.instance
+		}
+		if p.RequestID == "" {
+			p.RequestID = request.requestID
+		}
+	}
+
+	w.Header().Set("Content-Type", ProblemMediaType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// FieldErrors lists the domain.FieldErrors within err as problem fields,
+// their pointers under prefix, e.g. "/3" for the fourth item of a list.
+func FieldErrors(err error, prefix string) []ProblemField {
+	var fields []ProblemField
+	var walk func(error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case *domain.FieldError:
+			fields = append(fields, ProblemField{Pointer: prefix + "/" + e.Field, Detail: e.Err.Error()})
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				walk(err)
+			}
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		}
+	}
+	walk(err)
+	return fields
+}
@@ -0,0 +1,69 @@
+package util
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the ID of a request, both ways.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the request IDs accepted from clients.
+const maxRequestIDLength = 128
+
+// requestWriter carries what error responses report about their request.
+type requestWriter struct {
+	http.ResponseWriter
+	requestID string
+	instance  string
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *requestWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// RequestID gives every request an ID, the client's X-Request-ID when it
+// sends a usable one, and echoes it in the response. Error responses of
+// handlers below it report the ID and the request path.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(&requestWriter{ResponseWriter: w, requestID: id, instance: r.URL.Path}, r)
+	})
+}
+
+// validRequestID accepts short IDs of visible ASCII characters, which are
+// safe to log and to send back.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// unwrapWriter finds the writer of type T among w and the writers it
+// wraps, such as the request details RequestID attached to w.
+func unwrapWriter[T http.ResponseWriter](w http.ResponseWriter) (T, bool) {
+	for {
+		if found, ok := w.(T); ok {
+			return found, true
+		}
+		wrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			var zero T
+			return zero, false
+		}
+		w = wrapper.Unwrap()
+	}
+}
@@ -1,37 +1,142 @@
 package util
 
 import (
+	"bytes"
 	"encoding/json"
+	"encoding/xml"
 	"net/http"
+	"strings"
 )
 
 type APIResponse struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+	XMLName xml.Name    `json:"-" xml:"response"`
+	Success bool        `json:"success" xml:"success"`
+	Message string      `json:"message" xml:"message"`
+	Data    interface{} `json:"data,omitempty" xml:"data,omitempty"`
 }
 
-func WriteSuccessResponse(w http.ResponseWriter, data interface{}, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+// ListResponse is the envelope of a page of a list.
+type ListResponse struct {
+	XMLName xml.Name    `json:"-" xml:"response"`
+	Success bool        `json:"success" xml:"success"`
+	Message string      `json:"message" xml:"message"`
+	Data    interface{} `json:"data" xml:"data"`
+	ListMeta
+}
+
+// ListMeta places a page within the whole list. Offset is set for pages
+// selected by offset and Cursor for pages selected by cursor.
+type ListMeta struct {
+	Total  int       `json:"total" xml:"total"`
+	Limit  int       `json:"limit" xml:"limit"`
+	Offset *int      `json:"offset,omitempty" xml:"offset,omitempty"`
+	Cursor string    `json:"cursor,omitempty" xml:"cursor,omitempty"`
+	Links  PageLinks `json:"links" xml:"links"`
+}
 
+// PageLinks point to the first page of a list response and the pages
+// around it.
+type PageLinks struct {
+	First string `json:"first" xml:"first"`
+	Next  string `json:"next,omitempty" xml:"next,omitempty"`
+	Prev  string `json:"prev,omitempty" xml:"prev,omitempty"`
+}
+
+// header renders the links as an RFC 8288 Link header value.
+func (l PageLinks) header() string {
+	var links []string
+	for _, link := range []struct{ rel, url string }{
+		{"first", l.First},
+		{"prev", l.Prev},
+		{"next", l.Next},
+	} {
+		if link.url != "" {
+			links = append(links, "<"+link.url+`>; rel="`+link.rel+`"`)
+		}
+	}
+	return strings.Join(links, ", ")
+}
+
+func WriteSuccessResponse(w http.ResponseWriter, data interface{}, message string) {
 	response := APIResponse{
 		Success: true,
 		Message: message,
 		Data:    data,
 	}
 
-	json.NewEncoder(w).Encode(response)
+	writeEncoded(w, http.StatusOK, response)
 }
 
+// WriteErrorResponse writes a problem with statusCode and message as its
+// detail.
 func WriteErrorResponse(w http.ResponseWriter, statusCode int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
+	WriteProblem(w, Problem{Status: statusCode, Detail: message})
+}
+
+// WriteResponse writes data in the response envelope with any status, for
+// responses such as batch results that carry data whether or not they
+// succeeded. Error statuses are written as a problem carrying data.
+func WriteResponse(w http.ResponseWriter, statusCode int, success bool, data interface{}, message string) {
+	if statusCode >= http.StatusBadRequest {
+		WriteProblem(w, Problem{Status: statusCode, Detail: message, Data: data})
+		return
+	}
 
 	response := APIResponse{
-		Success: false,
+		Success: success,
 		Message: message,
+		Data:    data,
 	}
 
-	json.NewEncoder(w).Encode(response)
+	writeEncoded(w, statusCode, response)
+}
+
+// WriteListResponse writes a page of a list in the list envelope and
+// repeats its links in a Link header.
+func WriteListResponse(w http.ResponseWriter, data interface{}, meta ListMeta, message string) {
+	if link := meta.Links.header(); link != "" {
+		w.Header().Set("Link", link)
+	}
+
+	response := ListResponse{
+		Success:  true,
+		Message:  message,
+		Data:     data,
+		ListMeta: meta,
+	}
+
+	writeEncoded(w, http.StatusOK, response)
+}
+
+// writeEncoded writes v with the first encoder chosen by Negotiate that can
+// encode it, or as JSON when the request was not negotiated. A response no
+// chosen encoder can encode is written as JSON too: the handler has already
+// run by then, so a 406 would hide what it did.
+func writeEncoded(w http.ResponseWriter, statusCode int, v interface{}) {
+	negotiated, ok := unwrapWriter[*negotiatedWriter](w)
+	if !ok {
+		writeJSON(w, statusCode, v)
+		return
+	}
+
+	var buf bytes.Buffer
+	for _, enc := range negotiated.encoders {
+		buf.Reset()
+		if err := enc.Encode(&buf, v); err != nil {
+			continue
+		}
+		w.Header().Set("Content-Type", enc.MediaTypes()[0])
+		w.WriteHeader(statusCode)
+		w.Write(buf.Bytes())
+		return
+	}
+
+	writeJSON(w, statusCode, v)
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	json.NewEncoder(w).Encode(v)
 }
@@ -0,0 +1,110 @@
+package util
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	"github.com/izymalhaw/go-crud/yishakterefe/internal/domain"
+)
+
+// validate checks request bodies against their validate tags. Fields are
+// named by their json tags in messages and pointers.
+var validate = validator.New(validator.WithRequiredStructEnabled())
+
+// translator renders the messages of failed validations.
+var translator, _ = ut.New(en.New()).GetTranslator("en")
+
+// customValidations are the validate tags of this API, with the message of
+// a field that fails each. {0} stands for the field name.
+var customValidations = []struct {
+	tag     string
+	fn      validator.Func
+	message string
+}{
+	{
+		tag:     "personname",
+		fn:      func(fl validator.FieldLevel) bool { return domain.ValidPersonName(fl.Field().String()) },
+		message: "{0} may only contain letters, spaces, apostrophes, hyphens and periods",
+	},
+	{
+		tag:     "hobby",
+		fn:      func(fl validator.FieldLevel) bool { return domain.ValidHobby(fl.Field().String()) },
+		message: "{0} must be letters, digits, spaces or - ' & + . characters",
+	},
+	{
+		tag: "uniquefold",
+		fn: func(fl validator.FieldLevel) bool {
+			hobbies, ok := fl.Field().Interface().([]string)
+			return ok && !domain.HasDuplicateHobbies(hobbies)
+		},
+		message: "{0} must not repeat an item, ignoring case",
+	},
+}
+
+func init() {
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	if err := en_translations.RegisterDefaultTranslations(validate, translator); err != nil {
+		panic(err)
+	}
+
+	for _, custom := range customValidations {
+		message := custom.message
+		if err := validate.RegisterValidation(custom.tag, custom.fn); err != nil {
+			panic(err)
+		}
+		err := validate.RegisterTranslation(custom.tag, translator,
+			func(trans ut.Translator) error { return trans.Add(custom.tag, message, true) },
+			func(trans ut.Translator, fe validator.FieldError) string {
+				text, _ := trans.T(fe.Tag(), fe.Field())
+				return text
+			})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Validate checks the struct v points to against its validate tags. It
+// returns domain.FieldErrors of kind domain.ErrValidation, one per failed
+// field with a translated message, or nil when v is valid.
+func Validate(v interface{}) error {
+	err := validate.Struct(v)
+	var failed validator.ValidationErrors
+	if !errors.As(err, &failed) {
+		var invalid *validator.InvalidValidationError
+		if errors.As(err, &invalid) {
+			// v is not a struct; there is nothing to check.
+			return nil
+		}
+		return err
+	}
+
+	errs := make(domain.FieldErrors, len(failed))
+	for i, fe := range failed {
+		errs[i] = &domain.FieldError{
+			Field: fieldPath(fe.Namespace()),
+			Err:   domain.NewError(domain.ErrValidation, fe.Translate(translator)),
+		}
+	}
+	return errs
+}
+
+// fieldPath turns the namespace of a failed field, such as
+// "PersonRequest.hobbies[2]", into a domain.FieldError path such as
+// "hobbies/2".
+func fieldPath(namespace string) string {
+	_, path, _ := strings.Cut(namespace, ".")
+	path = strings.NewReplacer("[", "/", "]", "", ".", "/").Replace(path)
+	return path
+}